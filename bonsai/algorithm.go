@@ -0,0 +1,55 @@
+package bonsai
+
+import "strings"
+
+// Algorithm names a version of the rules a tree grows by. A seed grows the
+// same tree under the same algorithm forever: once a version is released,
+// it keeps rolling the same dice in the same order, and changes to how
+// trees grow go in as a new version instead.
+type Algorithm string
+
+const (
+	AlgorithmV1 Algorithm = "v1" // The original cbonsai rules
+
+	// LatestAlgorithm is what new trees grow by when no algorithm is set
+	LatestAlgorithm = AlgorithmV1
+)
+
+// growthRules are the parts of growth that differ between algorithms.
+// Everything else that rolls dice, like the leaves of ChooseChar and the
+// colors of GetBranchColor, is shared by every version so far; a version
+// that changes it has to move it in here first.
+type growthRules interface {
+	// deltas picks the move of the next step of a branch
+	deltas(bt *Tree, branchType BranchType, life, age int) (int, int)
+	// sprout decides whether the step of b starts a new branch, and
+	// returns its type and life if it does
+	sprout(bt *Tree, b *branchFrame) (BranchType, int, bool)
+}
+
+// algorithms holds the rules of every released algorithm
+var algorithms = map[Algorithm]growthRules{
+	AlgorithmV1: rulesV1{},
+}
+
+// Algorithms returns the names of the growth algorithms, oldest first
+func Algorithms() []string {
+	return []string{string(AlgorithmV1)}
+}
+
+// ParseAlgorithm checks an algorithm given by name
+func ParseAlgorithm(s string) (Algorithm, bool) {
+	algorithm := Algorithm(strings.ToLower(s))
+	if _, ok := algorithms[algorithm]; ok {
+		return algorithm, true
+	}
+	return "", false
+}
+
+// algorithm returns the algorithm the tree grows by
+func (c *Config) algorithm() Algorithm {
+	if c.Algorithm == "" {
+		return LatestAlgorithm
+	}
+	return c.Algorithm
+}
@@ -0,0 +1,157 @@
+package bonsai
+
+// rulesV1 grows trees the way cbonsai does. Trees people have shared depend
+// on every roll here, so nothing in this file may change.
+type rulesV1 struct{}
+
+// deltas calculates movement deltas based on branch type and age
+func (rulesV1) deltas(bt *Tree, branchType BranchType, life, age int) (int, int) {
+	dx, dy := 0, 0
+
+	switch branchType {
+	case Trunk:
+		if age <= 2 || life < 4 {
+			dy = 0
+			dx = bt.rng.Intn(3) - 1
+		} else if age < (bt.config.Multiplier * 3) {
+			if age%(int(float64(bt.config.Multiplier)*0.5+0.5)) == 0 {
+				dy = -1
+			} else {
+				dy = 0
+			}
+			dice := bt.rng.Intn(10)
+			switch {
+			case dice == 0:
+				dx = -2
+			case dice <= 3:
+				dx = -1
+			case dice <= 5:
+				dx = 0
+			case dice <= 8:
+				dx = 1
+			case dice == 9:
+				dx = 2
+			}
+		} else {
+			if bt.rng.Intn(10) > 2 {
+				dy = -1
+			} else {
+				dy = 0
+			}
+			dx = bt.rng.Intn(3) - 1
+		}
+
+	case ShootLeft:
+		dice := bt.rng.Intn(10)
+		switch {
+		case dice <= 1:
+			dy = -1
+		case dice <= 7:
+			dy = 0
+		default:
+			dy = 1
+		}
+		dice = bt.rng.Intn(10)
+		switch {
+		case dice <= 1:
+			dx = -2
+		case dice <= 5:
+			dx = -1
+		case dice <= 8:
+			dx = 0
+		default:
+			dx = 1
+		}
+
+	case ShootRight:
+		dice := bt.rng.Intn(10)
+		switch {
+		case dice <= 1:
+			dy = -1
+		case dice <= 7:
+			dy = 0
+		default:
+			dy = 1
+		}
+		dice = bt.rng.Intn(10)
+		switch {
+		case dice <= 1:
+			dx = 2
+		case dice <= 5:
+			dx = 1
+		case dice <= 8:
+			dx = 0
+		default:
+			dx = -1
+		}
+
+	case Dying:
+		dice := bt.rng.Intn(10)
+		switch {
+		case dice <= 1:
+			dy = -1
+		case dice <= 8:
+			dy = 0
+		default:
+			dy = 1
+		}
+		dice = bt.rng.Intn(15)
+		switch {
+		case dice == 0:
+			dx = -3
+		case dice <= 2:
+			dx = -2
+		case dice <= 5:
+			dx = -1
+		case dice <= 8:
+			dx = 0
+		case dice <= 11:
+			dx = 1
+		case dice <= 13:
+			dx = 2
+		default:
+			dx = 3
+		}
+
+	case Dead:
+		dice := bt.rng.Intn(10)
+		switch {
+		case dice <= 2:
+			dy = -1
+		case dice <= 6:
+			dy = 0
+		default:
+			dy = 1
+		}
+		dx = bt.rng.Intn(3) - 1
+	}
+
+	return dx, dy
+}
+
+// sprout decides whether the step of b starts a new branch, and returns
+// its type and life if it does
+func (rulesV1) sprout(bt *Tree, b *branchFrame) (BranchType, int, bool) {
+	life := b.life
+	switch {
+	case life < 3:
+		return Dead, life, true
+	case b.branchType == Trunk && life < (bt.config.Multiplier+2):
+		return Dying, life, true
+	case (b.branchType == ShootLeft || b.branchType == ShootRight) && life < (bt.config.Multiplier+2):
+		return Dying, life, true
+	case b.branchType == Trunk && (bt.rng.Intn(3) == 0 || life%bt.config.Multiplier == 0):
+		if bt.rng.Intn(8) == 0 && life > 7 {
+			b.shootCooldown = bt.config.Multiplier * 2
+			return Trunk, life + bt.rng.Intn(5) - 2, true
+		} else if b.shootCooldown <= 0 {
+			b.shootCooldown = bt.config.Multiplier * 2
+			bt.shoots++
+			if bt.shoots%2 == 0 {
+				return ShootLeft, life + bt.config.Multiplier, true
+			}
+			return ShootRight, life + bt.config.Multiplier, true
+		}
+	}
+	return 0, 0, false
+}
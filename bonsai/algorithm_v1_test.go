@@ -0,0 +1,117 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write golden files that are missing")
+
+// goldenCase is a tree with a golden file in testdata/golden
+type goldenCase struct {
+	name   string
+	config func(*Config)
+}
+
+// goldenV1 are the trees v1 must keep growing. Cases may be added, but the
+// golden files of existing ones must never change.
+func goldenV1() []goldenCase {
+	var cases []goldenCase
+	for _, seed := range []int64{1, 42, 1234, 31337} {
+		for _, size := range [][2]int{{80, 24}, {120, 40}, {40, 16}} {
+			cases = append(cases, goldenCase{
+				name: fmt.Sprintf("seed%d-%dx%d", seed, size[0], size[1]),
+				config: func(c *Config) {
+					c.Seed = seed
+					c.Width, c.Height = size[0], size[1]
+				},
+			})
+		}
+	}
+	for _, style := range []DrawStyle{StyleBraille, StylePixels} {
+		cases = append(cases, goldenCase{
+			name: fmt.Sprintf("seed42-%s", style),
+			config: func(c *Config) {
+				c.Seed = 42
+				c.Style = style
+			},
+		})
+	}
+	for _, g := range []struct{ multiplier, life int }{{1, 40}, {2, 100}, {12, 100}, {20, 100}} {
+		cases = append(cases, goldenCase{
+			name: fmt.Sprintf("seed42-multiplier%d-life%d", g.multiplier, g.life),
+			config: func(c *Config) {
+				c.Seed = 42
+				c.Multiplier = g.multiplier
+				c.LifeStart = g.life
+				c.Width, c.Height = 120, 40
+			},
+		})
+	}
+	return append(cases,
+		goldenCase{"seed42-leaves", func(c *Config) {
+			c.Seed = 42
+			c.Leaves = []string{"&:5", "*:2", "@"}
+			c.DyingLeaves = []string{"~"}
+			c.DeadLeaves = []string{".:3", ","}
+		}},
+		goldenCase{"seed42-no-color", func(c *Config) {
+			c.Seed = 42
+			c.UseColors = false
+		}},
+	)
+}
+
+func TestGoldenV1(t *testing.T) {
+	dir := filepath.Join("testdata", "golden", string(AlgorithmV1))
+	cases := goldenV1()
+	known := make(map[string]bool)
+	for _, tc := range cases {
+		known[tc.name+".txt"] = true
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Algorithm = AlgorithmV1
+			tc.config(config)
+			tree := NewTree(config)
+			if err := tree.GrowWith(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			if err := tree.RenderWith(context.Background(), NewTextRenderer(&got, true)); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(dir, tc.name+".txt")
+			want, err := os.ReadFile(path)
+			if os.IsNotExist(err) && *update {
+				if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				t.Logf("wrote %s", path)
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v (run with -update to write missing golden files)", err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("seed grows a different tree than %s:\n%s", path, got.String())
+			}
+		})
+	}
+
+	// A golden file without a case is one that was renamed or lost its case
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if !known[f.Name()] {
+			t.Errorf("golden file %s has no case", f.Name())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package bonsai
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Background is the kind of terminal background a palette is picked for
+type Background string
+
+const (
+	BackgroundDark  Background = "dark"  // Light colors on a dark background (default)
+	BackgroundLight Background = "light" // Dark colors on a light background
+)
+
+// ParseBackground checks a background given by name
+func ParseBackground(s string) (Background, bool) {
+	switch bg := Background(strings.ToLower(s)); bg {
+	case BackgroundDark, BackgroundLight:
+		return bg, true
+	}
+	return "", false
+}
+
+// BackgroundQuery asks a terminal for its background color with OSC 11.
+// It is followed by a request for the primary device attributes, which
+// every terminal answers, so a terminal that doesn't know OSC 11 can be
+// told from a slow one.
+const BackgroundQuery = "\033]11;?\033\\\033[c"
+
+// ParseBackgroundReply finds the background color in what a terminal sent
+// back for BackgroundQuery, as in "\033]11;rgb:1e1e/1e1e/2e2e\033\\"
+func ParseBackgroundReply(reply string) (color.RGBA, bool) {
+	_, rest, ok := strings.Cut(reply, "\033]11;rgb:")
+	if !ok {
+		return color.RGBA{}, false
+	}
+	if end := strings.IndexAny(rest, "\033\a"); end >= 0 {
+		rest = rest[:end]
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 {
+		return color.RGBA{}, false
+	}
+	var rgb [3]uint8
+	for i, part := range parts {
+		// Each channel has 1 to 4 hex digits, scaled to 8 bits
+		v, err := strconv.ParseUint(part, 16, 16)
+		if err != nil || len(part) < 1 || len(part) > 4 {
+			return color.RGBA{}, false
+		}
+		rgb[i] = uint8(v * 255 / (1<<(4*len(part)) - 1))
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, true
+}
+
+// BackgroundOf tells whether c is a light or a dark background
+func BackgroundOf(c color.RGBA) Background {
+	if toLab(c)[0] > 50 {
+		return BackgroundLight
+	}
+	return BackgroundDark
+}
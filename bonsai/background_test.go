@@ -0,0 +1,49 @@
+package bonsai
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseBackgroundReply(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  color.RGBA
+		ok    bool
+	}{
+		{"\033]11;rgb:1e1e/1e1e/2e2e\033\\\033[?62;22c", color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, true},
+		{"\033]11;rgb:ffff/ffff/ffff\a", color.RGBA{0xff, 0xff, 0xff, 0xff}, true},
+		{"\033]11;rgb:f/8/0\033\\", color.RGBA{0xff, 0x88, 0x00, 0xff}, true},
+		{"\033]11;rgb:fd/f6/e3\033\\", color.RGBA{0xfd, 0xf6, 0xe3, 0xff}, true},
+		{"noise\033]11;rgb:000/000/000\033\\", color.RGBA{0, 0, 0, 0xff}, true},
+		{"\033[?62;22c", color.RGBA{}, false}, // Only device attributes, no OSC 11
+		{"\033]11;rgb:ffff/ffff\033\\", color.RGBA{}, false},
+		{"\033]11;rgb:fffff/0/0\033\\", color.RGBA{}, false},
+		{"\033]11;rgb:zz/00/00\033\\", color.RGBA{}, false},
+		{"\033]11;rgb://0\033\\", color.RGBA{}, false},
+		{"", color.RGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseBackgroundReply(tt.reply)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseBackgroundReply(%q) = %v, %v, want %v, %v", tt.reply, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackgroundOf(t *testing.T) {
+	tests := []struct {
+		c    color.RGBA
+		want Background
+	}{
+		{color.RGBA{0, 0, 0, 0xff}, BackgroundDark},
+		{color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, BackgroundDark},
+		{color.RGBA{0xfd, 0xf6, 0xe3, 0xff}, BackgroundLight},
+		{color.RGBA{0xff, 0xff, 0xff, 0xff}, BackgroundLight},
+	}
+	for _, tt := range tests {
+		if got := BackgroundOf(tt.c); got != tt.want {
+			t.Errorf("BackgroundOf(%v) = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
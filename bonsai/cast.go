@@ -0,0 +1,78 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// CastWriter records everything written to it as an asciinema asciicast v2
+// file, so live output can be replayed in any asciinema-compatible player.
+// Every Write becomes one output event, timed relative to the header.
+type CastWriter struct {
+	w       io.Writer
+	start   time.Time
+	pending []byte // Incomplete UTF-8 sequence held back from the last Write
+}
+
+// castHeader is the first line of an asciicast v2 file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// NewCastWriter writes the asciicast header for a terminal of the given size
+// and returns a writer for the output events
+func NewCastWriter(w io.Writer, width, height int, env map[string]string) (*CastWriter, error) {
+	start := time.Now()
+	header, err := json.Marshal(castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Env:       env,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", header); err != nil {
+		return nil, err
+	}
+	return &CastWriter{w: w, start: start}, nil
+}
+
+// Write records p as an output event
+func (c *CastWriter) Write(p []byte) (int, error) {
+	elapsed := math.Round(time.Since(c.start).Seconds()*1e6) / 1e6
+
+	// Events must be valid UTF-8, so keep a split rune for the next Write
+	data := append(c.pending, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	c.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+
+	event, err := json.Marshal([]any{elapsed, "o", string(data[:cut])})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := fmt.Fprintf(c.w, "%s\n", event); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
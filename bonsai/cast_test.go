@@ -0,0 +1,51 @@
+package bonsai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestCastWriter(t *testing.T) {
+	var out bytes.Buffer
+	c, err := NewCastWriter(&out, 100, 30, map[string]string{"TERM": "xterm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := []byte("🌸")
+	writes := [][]byte{
+		[]byte("\033[2J"),
+		leaf[:2], // A rune split across writes is held back
+		leaf[2:],
+	}
+	for _, p := range writes {
+		if n, err := c.Write(p); err != nil || n != len(p) {
+			t.Fatalf("Write(%q) = %d, %v", p, n, err)
+		}
+	}
+
+	scanner := bufio.NewScanner(&out)
+	scanner.Scan()
+	var header castHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 100 || header.Height != 30 || header.Env["TERM"] != "xterm" {
+		t.Errorf("header %+v", header)
+	}
+	var events []string
+	for scanner.Scan() {
+		var event []any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		if len(event) != 3 || event[1] != "o" {
+			t.Fatalf("event %v", event)
+		}
+		events = append(events, event[2].(string))
+	}
+	if len(events) != 2 || events[0] != "\033[2J" || events[1] != "🌸" {
+		t.Errorf("events %q, want the escape and the whole leaf", events)
+	}
+}
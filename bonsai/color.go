@@ -0,0 +1,172 @@
+package bonsai
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Color constants for ANSI escape codes
+const (
+	ColorReset = "\033[0m"
+	ColorBold  = "\033[1m"
+
+	// Text colors
+	ColorBlack   = "\033[30m"
+	ColorRed     = "\033[31m"
+	ColorGreen   = "\033[32m"
+	ColorYellow  = "\033[33m"
+	ColorBlue    = "\033[34m"
+	ColorMagenta = "\033[35m"
+	ColorCyan    = "\033[36m"
+	ColorWhite   = "\033[37m"
+
+	// Bright colors
+	ColorBrightBlack   = "\033[90m"
+	ColorBrightRed     = "\033[91m"
+	ColorBrightGreen   = "\033[92m"
+	ColorBrightYellow  = "\033[93m"
+	ColorBrightBlue    = "\033[94m"
+	ColorBrightMagenta = "\033[95m"
+	ColorBrightCyan    = "\033[96m"
+	ColorBrightWhite   = "\033[97m"
+
+	// 256-color support
+	ColorBrown       = "\033[38;5;94m"  // Brown for branches
+	ColorDarkBrown   = "\033[38;5;52m"  // Dark brown for trunk
+	ColorLightBrown  = "\033[38;5;130m" // Light brown for branches
+	ColorDarkGreen   = "\033[38;5;22m"  // Dark green for leaves
+	ColorMediumGreen = "\033[38;5;28m"  // Medium green for leaves
+	ColorTerracotta  = "\033[38;5;166m" // Terracotta for pot
+	ColorOrange      = "\033[38;5;214m" // Orange/autumn leaves
+)
+
+// GetBranchColor returns the appropriate color for branch types, from the
+// palette in the config
+func (bt *Tree) GetBranchColor(branchType BranchType) string {
+	if !bt.config.UseColors {
+		return ""
+	}
+
+	switch branchType {
+	case Trunk:
+		return bt.palette.trunk.pick(bt.looks)
+	case ShootLeft, ShootRight:
+		return bt.palette.shoot.roll(bt.rng)
+	case Dying:
+		return bt.palette.leaf.roll(bt.rng)
+	case Dead:
+		return bt.palette.deadLeaf.roll(bt.rng)
+	}
+	return ""
+}
+
+// GetBaseColor returns the appropriate color for the pot/base
+func (bt *Tree) GetBaseColor() string {
+	if !bt.config.UseColors {
+		return ""
+	}
+	return bt.palette.pot.pick(bt.looks)
+}
+
+// ansiBasic holds the xterm values of the 16 basic ANSI colors
+var ansiBasic = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// xterm256 returns the RGB value of an entry in the xterm 256-color palette
+func xterm256(n int) color.RGBA {
+	switch {
+	case n < 16:
+		return ansiBasic[n]
+	case n < 232:
+		// 6x6x6 color cube
+		levels := [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+		n -= 16
+		return color.RGBA{levels[n/36], levels[n/6%6], levels[n%6], 0xff}
+	default:
+		// Grayscale ramp
+		v := uint8(8 + (n-232)*10)
+		return color.RGBA{v, v, v, 0xff}
+	}
+}
+
+// ansiBackground turns a foreground escape code into the matching
+// background one, or returns "" if it can't
+func ansiBackground(code string) string {
+	params, ok := strings.CutPrefix(code, "\033[")
+	if !ok {
+		return ""
+	}
+	if n, ok := strings.CutPrefix(params, "38;"); ok {
+		return "\033[48;" + n
+	}
+	v, err := strconv.Atoi(strings.TrimSuffix(params, "m"))
+	switch {
+	case err != nil:
+		return ""
+	case v >= 30 && v <= 37, v >= 90 && v <= 97:
+		return fmt.Sprintf("\033[%dm", v+10)
+	}
+	return ""
+}
+
+// ANSIToRGB maps a foreground or background escape code, such as
+// ColorBrightGreen, to the RGB value xterm would show for it
+func ANSIToRGB(code string) (color.RGBA, bool) {
+	params, ok := strings.CutPrefix(code, "\033[")
+	if !ok {
+		return color.RGBA{}, false
+	}
+	params, ok = strings.CutSuffix(params, "m")
+	if !ok {
+		return color.RGBA{}, false
+	}
+	if n, ok := strings.CutPrefix(params, "48;"); ok {
+		params = "38;" + n
+	}
+	if n, ok := strings.CutPrefix(params, "38;2;"); ok {
+		var rgb [3]uint8
+		parts := strings.Split(n, ";")
+		if len(parts) != 3 {
+			return color.RGBA{}, false
+		}
+		for i, part := range parts {
+			v, err := strconv.Atoi(part)
+			if err != nil || v < 0 || v > 255 {
+				return color.RGBA{}, false
+			}
+			rgb[i] = uint8(v)
+		}
+		return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, true
+	}
+	if n, ok := strings.CutPrefix(params, "38;5;"); ok {
+		if v, err := strconv.Atoi(n); err == nil && v >= 0 && v < 256 {
+			return xterm256(v), true
+		}
+		return color.RGBA{}, false
+	}
+	v, err := strconv.Atoi(params)
+	switch {
+	case err != nil:
+		return color.RGBA{}, false
+	case v >= 30 && v <= 37:
+		return ansiBasic[v-30], true
+	case v >= 40 && v <= 47:
+		return ansiBasic[v-40], true
+	case v >= 90 && v <= 97:
+		return ansiBasic[v-90+8], true
+	case v >= 100 && v <= 107:
+		return ansiBasic[v-100+8], true
+	}
+	return color.RGBA{}, false
+}
+
+// HexColor formats c as a CSS hex color
+func HexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
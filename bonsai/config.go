@@ -0,0 +1,209 @@
+// Package bonsai grows random bonsai trees, in the spirit of cbonsai.
+//
+// A Tree is grown from a Config and written to any io.Writer, so it can be
+// embedded in other programs without taking over the terminal.
+package bonsai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxGlyphWidth is the widest a single leaf or stroke may be, in columns
+const maxGlyphWidth = 4
+
+// BranchType represents different types of branches
+type BranchType int
+
+const (
+	Trunk BranchType = iota
+	ShootLeft
+	ShootRight
+	Dying
+	Dead
+)
+
+// LeafLayer says whether leaves are drawn in front of branches or behind
+// them. When it is empty, whatever grows last is on top.
+type LeafLayer string
+
+const (
+	LeavesFront  LeafLayer = "front"
+	LeavesBehind LeafLayer = "behind"
+)
+
+// Stacking order of the parts of the tree, see Cell.Z
+const (
+	zBehind  = -1 // Leaves behind branches
+	zFront   = 1  // Leaves in front of branches
+	zMessage = 2  // The message box, over everything
+)
+
+// Config holds all options that affect how a tree is grown
+type Config struct {
+	Live       bool            `json:"-"`          // Draw each step of growth as it happens
+	LifeStart  int             `json:"life"`       // Higher -> more growth (0-200)
+	Multiplier int             `json:"multiplier"` // Higher -> more branching (1-20)
+	BaseType   int             `json:"base"`       // Plant base to draw, 0 is none
+	Seed       int64           `json:"seed"`       // Seed for the random number generator
+	TimeStep   float64         `json:"-"`          // In live mode, seconds to wait between steps
+	Message    string          `json:"message"`    // Message attached to the tree
+	MessagePos MessagePosition `json:"messagePosition"`
+	Leaves     []string        `json:"leaves"` // Leaf glyphs, each optionally glyph:weight
+	Width      int             `json:"width"`  // Canvas width in cells
+	Height     int             `json:"height"` // Canvas height in cells
+	UseColors  bool            `json:"colors"`
+	Style      DrawStyle       `json:"style"`               // How the tree is drawn, text when empty
+	LeafLayer  LeafLayer       `json:"leafLayer,omitempty"` // Where leaves go where they meet branches
+
+	// Growth rules the seed is rolled by, the latest when empty
+	Algorithm Algorithm `json:"algorithm,omitempty"`
+
+	// Pacing of live mode: frames painted per second, 0 for one per step,
+	// and seconds the whole tree takes to grow, 0 to pace by TimeStep. Like
+	// Live and TimeStep it is how the tree is shown, so it isn't saved.
+	FPS      float64 `json:"-"`
+	Duration float64 `json:"-"`
+
+	// Glyphs for dying and dead branches, Leaves when empty
+	DyingLeaves []string `json:"dyingLeaves,omitempty"`
+	DeadLeaves  []string `json:"deadLeaves,omitempty"`
+
+	// Glyphs the trunk and shoots are drawn with
+	TrunkGlyphs Strokes `json:"trunkGlyphs"`
+	ShootGlyphs Strokes `json:"shootGlyphs"`
+
+	// Pots to pick from with BaseType, the classic ones when empty
+	Pots []Pot `json:"pots,omitempty"`
+
+	// Colors of the tree when UseColors is set, classic for parts left empty
+	Palette Palette `json:"palette"`
+	Shading Shading `json:"shading,omitempty"` // How colors are picked, random when empty
+
+	// Terminal background the colors are picked for, dark when empty. It
+	// belongs to the terminal rather than the tree, so it isn't saved.
+	Background Background `json:"-"`
+
+	// Record the Scene of the tree while it grows, for Tree.Scene and
+	// Tree.WriteJSON. It slows big trees down, so it is off unless asked for.
+	RecordScene bool `json:"-"`
+}
+
+// DefaultConfig returns a Config with the same defaults as the gobonsai CLI
+func DefaultConfig() *Config {
+	return &Config{
+		LifeStart:  32,
+		Multiplier: 5,
+		BaseType:   1,
+		TimeStep:   0.03,
+		MessagePos: MessageRight,
+		Style:      StyleText,
+		Leaves:     []string{"&", "*", "o", "@", "%"},
+		Width:      80,
+		Height:     24,
+		UseColors:  true,
+	}
+}
+
+// Validate reports whether the configuration can be used to grow a tree
+func (c *Config) Validate() error {
+	if c.LifeStart < 0 || c.LifeStart > 200 {
+		return errors.New("life must be between 0 and 200")
+	}
+	if c.Multiplier < 1 || c.Multiplier > 20 {
+		return errors.New("multiplier must be between 1 and 20")
+	}
+	if pots := len(c.pots()); c.BaseType < 0 || c.BaseType > pots {
+		return fmt.Errorf("base type must be between 0 and %d", pots)
+	}
+	if c.TimeStep < 0 {
+		return errors.New("time step must be non-negative")
+	}
+	if c.FPS < 0 {
+		return errors.New("fps must be non-negative")
+	}
+	if c.Duration < 0 {
+		return errors.New("duration must be non-negative")
+	}
+	if _, ok := algorithms[c.algorithm()]; !ok {
+		return fmt.Errorf("algorithm must be one of %s", strings.Join(Algorithms(), ", "))
+	}
+	switch c.MessagePos {
+	case "", MessageRight, MessageLeft, MessageBelow:
+	default:
+		return errors.New("message position must be right, left, or below")
+	}
+	switch c.Style {
+	case "", StyleText, StyleBraille, StylePixels:
+	default:
+		return errors.New("style must be text, braille, or pixels")
+	}
+	if err := validateLeaves(c.Leaves); err != nil {
+		return err
+	}
+	if err := validateLeaves(c.DyingLeaves); err != nil {
+		return fmt.Errorf("dying %w", err)
+	}
+	if err := validateLeaves(c.DeadLeaves); err != nil {
+		return fmt.Errorf("dead %w", err)
+	}
+	if err := c.TrunkGlyphs.validate(); err != nil {
+		return fmt.Errorf("trunk glyphs: %w", err)
+	}
+	if err := c.ShootGlyphs.validate(); err != nil {
+		return fmt.Errorf("shoot glyphs: %w", err)
+	}
+	switch c.LeafLayer {
+	case "", LeavesFront, LeavesBehind:
+	default:
+		return errors.New("leaf layer must be front or behind")
+	}
+	switch c.Shading {
+	case "", ShadingRandom, ShadingGradient:
+	default:
+		return errors.New("shading must be random or gradient")
+	}
+	switch c.Background {
+	case "", BackgroundDark, BackgroundLight:
+	default:
+		return errors.New("background must be light or dark")
+	}
+	if err := c.Palette.validate(); err != nil {
+		return fmt.Errorf("theme %w", err)
+	}
+	for i, pot := range c.Pots {
+		if err := pot.validate(); err != nil {
+			return fmt.Errorf("pot %d %w", i+1, err)
+		}
+	}
+	if c.Width <= 0 || c.Height <= 0 {
+		return errors.New("canvas size must be positive")
+	}
+	return nil
+}
+
+// ParseLeaves splits a comma-delimited list of leaves, as given to --leaf.
+// A leaf can be any short string, including emoji and wide characters, and
+// may end in :weight to make it more or less common, as in "&:5,*:2,@".
+func ParseLeaves(s string) ([]string, error) {
+	leaves := strings.Split(s, ",")
+	if err := validateLeaves(leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// validateLeaves checks that every leaf can be drawn
+func validateLeaves(leaves []string) error {
+	_, err := parseGlyphSet("leaf", leaves)
+	return err
+}
+
+// pots returns the pots BaseType picks from
+func (c *Config) pots() []Pot {
+	if len(c.Pots) > 0 {
+		return c.Pots
+	}
+	return classicPots
+}
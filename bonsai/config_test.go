@@ -0,0 +1,76 @@
+package bonsai
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"life 0", func(c *Config) { c.LifeStart = 0 }, false},
+		{"life 201", func(c *Config) { c.LifeStart = 201 }, true},
+		{"multiplier 0", func(c *Config) { c.Multiplier = 0 }, true},
+		{"multiplier 1", func(c *Config) { c.Multiplier = 1 }, false},
+		{"multiplier 20", func(c *Config) { c.Multiplier = 20 }, false},
+		{"multiplier 21", func(c *Config) { c.Multiplier = 21 }, true},
+		{"no base", func(c *Config) { c.BaseType = 0 }, false},
+		{"unknown base", func(c *Config) { c.BaseType = 99 }, true},
+		{"negative time", func(c *Config) { c.TimeStep = -1 }, true},
+		{"message position", func(c *Config) { c.MessagePos = "above" }, true},
+		{"style", func(c *Config) { c.Style = "ascii" }, true},
+		{"algorithm", func(c *Config) { c.Algorithm = "v0" }, true},
+		{"empty leaf", func(c *Config) { c.Leaves = []string{"&", ""} }, true},
+		{"leaf weights all 0", func(c *Config) { c.Leaves = []string{"&:0", "*:0"} }, true},
+		{"empty canvas", func(c *Config) { c.Width = 0 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.change(config)
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// Anything that validates must grow
+			if err := NewTree(config).GrowWith(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestParseLeaves(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"&,*,o", []string{"&", "*", "o"}, false},
+		{"&:5,*:2,@", []string{"&:5", "*:2", "@"}, false},
+		{"🌸,🍃", []string{"🌸", "🍃"}, false},
+		{"&:x,a:b:2", []string{"&:x", "a:b:2"}, false},
+		{"&,", nil, true},
+		{"&:0", nil, true},
+		{"&:-1", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLeaves(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLeaves(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("ParseLeaves(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package bonsai
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is how many colors a terminal can show
+type ColorDepth int
+
+const (
+	DepthNone      ColorDepth = iota // No colors at all
+	Depth16                          // The 16 basic ANSI colors
+	Depth256                         // The xterm 256-color palette
+	DepthTrueColor                   // 24-bit RGB
+)
+
+// String returns the name ParseColorDepth accepts for d
+func (d ColorDepth) String() string {
+	switch d {
+	case DepthNone:
+		return "none"
+	case Depth16:
+		return "16"
+	case Depth256:
+		return "256"
+	}
+	return "truecolor"
+}
+
+// ParseColorDepth reads a color depth given by name
+func ParseColorDepth(s string) (ColorDepth, bool) {
+	switch strings.ToLower(s) {
+	case "none":
+		return DepthNone, true
+	case "16":
+		return Depth16, true
+	case "256":
+		return Depth256, true
+	case "truecolor", "24bit":
+		return DepthTrueColor, true
+	}
+	return DepthNone, false
+}
+
+// DetectColorDepth guesses what a terminal can show from its environment,
+// the way most terminal programs do. NO_COLOR turns colors off, as does
+// output that isn't a terminal; COLORTERM announces truecolor, and TERM
+// tells 256-color terminals from the rest.
+func DetectColorDepth(getenv func(string) string, tty bool) ColorDepth {
+	if getenv("NO_COLOR") != "" || !tty {
+		return DepthNone
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return DepthTrueColor
+	}
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case term == "dumb":
+		return DepthNone
+	case strings.HasSuffix(term, "-direct"):
+		return DepthTrueColor
+	case strings.Contains(term, "256color"):
+		return Depth256
+	}
+	return Depth16
+}
+
+// codeDepth returns the depth a color escape code needs
+func codeDepth(code string) ColorDepth {
+	params := strings.TrimPrefix(code, "\033[")
+	switch {
+	case strings.HasPrefix(params, "38;2;"), strings.HasPrefix(params, "48;2;"):
+		return DepthTrueColor
+	case strings.HasPrefix(params, "38;5;"), strings.HasPrefix(params, "48;5;"):
+		return Depth256
+	}
+	return Depth16
+}
+
+// Convert returns the color escape code that shows code best at depth d:
+// the code itself when d can show it, otherwise the perceptually nearest
+// color d has. Codes that aren't colors are kept, except at DepthNone.
+func (d ColorDepth) Convert(code string) string {
+	if code == "" || d == DepthNone {
+		return ""
+	}
+	if codeDepth(code) <= d {
+		return code
+	}
+	c, ok := ANSIToRGB(code)
+	if !ok {
+		return code
+	}
+	background := strings.HasPrefix(code, "\033[48;")
+
+	if d == Depth256 {
+		n := nearestXterm(c, 16, 256) // The first 16 differ from terminal to terminal
+		if background {
+			return "\033[48;5;" + strconv.Itoa(n) + "m"
+		}
+		return "\033[38;5;" + strconv.Itoa(n) + "m"
+	}
+	n := nearestXterm(c, 0, 16)
+	base := 30
+	if n >= 8 {
+		base, n = 90, n-8
+	}
+	if background {
+		base += 10
+	}
+	return "\033[" + strconv.Itoa(base+n) + "m"
+}
+
+// xtermLab holds the xterm palette in CIELAB, for nearestXterm
+var xtermLab = func() (t [256][3]float64) {
+	for i := range t {
+		t[i] = toLab(xterm256(i))
+	}
+	return t
+}()
+
+// nearestXterm returns the entry of the xterm palette between from and to
+// that looks closest to c
+func nearestXterm(c color.RGBA, from, to int) int {
+	want := toLab(c)
+	best, bestDist := from, math.Inf(1)
+	for i := from; i < to; i++ {
+		have := xtermLab[i]
+		dl, da, db := want[0]-have[0], want[1]-have[1], want[2]-have[2]
+		if dist := dl*dl + da*da + db*db; dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// toLab converts an sRGB color to CIELAB, where distances follow how
+// different colors look rather than how their values differ
+func toLab(c color.RGBA) [3]float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+
+	// XYZ relative to the D65 white point
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+// Downsample wraps r so the colors of every cell drawn to it are converted
+// to depth. Terminals that can't show a color often show a wrong one, or
+// garbage, so output meant for a terminal should go through it.
+func Downsample(r Renderer, depth ColorDepth) Renderer {
+	if depth == DepthTrueColor {
+		return r
+	}
+	return &downsampler{r: r, depth: depth, codes: make(map[string]string)}
+}
+
+// downsampler converts colors on their way to another renderer
+type downsampler struct {
+	r     Renderer
+	depth ColorDepth
+	codes map[string]string // Conversions done so far
+}
+
+// BeginFrame starts a frame
+func (d *downsampler) BeginFrame() error {
+	return d.r.BeginFrame()
+}
+
+// SetCell converts the colors of the cell and passes it on. Without colors
+// attributes go too, so the output is plain text.
+func (d *downsampler) SetCell(x, y int, cell Cell) error {
+	cell.Color = d.convert(cell.Color)
+	cell.Background = d.convert(cell.Background)
+	if d.depth == DepthNone {
+		cell.Attrs = 0
+	}
+	return d.r.SetCell(x, y, cell)
+}
+
+// EndFrame ends the frame
+func (d *downsampler) EndFrame() error {
+	return d.r.EndFrame()
+}
+
+// convert converts a code, remembering the result
+func (d *downsampler) convert(code string) string {
+	if code == "" {
+		return ""
+	}
+	if c, ok := d.codes[code]; ok {
+		return c
+	}
+	c := d.depth.Convert(code)
+	d.codes[code] = c
+	return c
+}
@@ -0,0 +1,99 @@
+package bonsai
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		depth ColorDepth
+		in    string
+		want  string
+	}{
+		{DepthTrueColor, "\033[38;2;255;0;0m", "\033[38;2;255;0;0m"},
+		{Depth256, "\033[38;2;255;0;0m", "\033[38;5;196m"},
+		{Depth16, "\033[38;2;255;0;0m", "\033[91m"},
+		{DepthNone, "\033[38;2;255;0;0m", ""},
+		{Depth256, "\033[38;2;0;135;0m", "\033[38;5;28m"},
+		{Depth256, "\033[38;5;22m", "\033[38;5;22m"}, // Already fits
+		{Depth16, "\033[38;5;22m", "\033[32m"},
+		{Depth16, "\033[38;5;240m", "\033[90m"},
+		{Depth16, "\033[93m", "\033[93m"},
+		{Depth256, "\033[48;2;0;0;255m", "\033[48;5;21m"}, // Backgrounds stay backgrounds
+		{Depth16, "\033[48;2;0;0;255m", "\033[44m"},
+		{Depth16, "", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.depth.Convert(tt.in); got != tt.want {
+			t.Errorf("%v.Convert(%q) = %q, want %q", tt.depth, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		tty  bool
+		want ColorDepth
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, false, DepthNone},
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, true, DepthNone},
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, true, DepthTrueColor},
+		{map[string]string{"TERM": "xterm-direct"}, true, DepthTrueColor},
+		{map[string]string{"TERM": "screen-256color"}, true, Depth256},
+		{map[string]string{"TERM": "xterm"}, true, Depth16},
+		{map[string]string{"TERM": "dumb"}, true, DepthNone},
+		{map[string]string{}, true, Depth16},
+	}
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		if got := DetectColorDepth(getenv, tt.tty); got != tt.want {
+			t.Errorf("DetectColorDepth(%v, tty %v) = %v, want %v", tt.env, tt.tty, got, tt.want)
+		}
+	}
+}
+
+func TestParseColorDepth(t *testing.T) {
+	for _, d := range []ColorDepth{DepthNone, Depth16, Depth256, DepthTrueColor} {
+		if got, ok := ParseColorDepth(d.String()); !ok || got != d {
+			t.Errorf("ParseColorDepth(%q) = %v, %v", d.String(), got, ok)
+		}
+	}
+	if _, ok := ParseColorDepth("8"); ok {
+		t.Error("ParseColorDepth(\"8\") succeeded")
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	cell := Cell{Glyph: "&", Width: 1, Color: "\033[38;2;255;0;0m", Background: "\033[48;2;0;0;255m", Attrs: AttrBold}
+	tests := []struct {
+		depth ColorDepth
+		want  Cell
+	}{
+		{DepthTrueColor, cell},
+		{Depth256, Cell{Glyph: "&", Width: 1, Color: "\033[38;5;196m", Background: "\033[48;5;21m", Attrs: AttrBold}},
+		{Depth16, Cell{Glyph: "&", Width: 1, Color: "\033[91m", Background: "\033[44m", Attrs: AttrBold}},
+		{DepthNone, Cell{Glyph: "&", Width: 1}},
+	}
+	for _, tt := range tests {
+		var b Buffer
+		r := Downsample(&b, tt.depth)
+		if err := r.BeginFrame(); err != nil {
+			t.Fatal(err)
+		}
+		for x := range 2 { // The second converts from the cache
+			if err := r.SetCell(x, 0, cell); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.EndFrame(); err != nil {
+			t.Fatal(err)
+		}
+		for x := range 2 {
+			if got := b.Cell(x, 0); got != tt.want {
+				t.Errorf("%v: cell %d = %+v, want %+v", tt.depth, x, got, tt.want)
+			}
+		}
+		if b.Frames() != 1 {
+			t.Errorf("%v: %d frames passed on, want 1", tt.depth, b.Frames())
+		}
+	}
+}
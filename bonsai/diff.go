@@ -0,0 +1,147 @@
+package bonsai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// DiffRenderer draws to a terminal like TerminalRenderer, but remembers
+// what the screen shows and writes only the cells a frame changed. Runs of
+// cells in the same style share their escape codes, the cursor is moved
+// only when the next change isn't where it already is, and every frame goes
+// out in a single write. This keeps slow links fast and stops flicker when
+// one tree replaces another.
+//
+// It assumes the screen is blank when it starts drawing.
+type DiffRenderer struct {
+	w      io.Writer
+	buf    bytes.Buffer
+	screen Buffer    // What the terminal shows
+	next   Buffer    // What it shows once the frame ends
+	dirty  []rowSpan // Columns of each row set during the frame
+
+	// Terminal state while a frame is written
+	x, y  int  // Cursor position, -1 when unknown
+	style Cell // Colors and attributes in effect
+}
+
+// rowSpan is a range of columns, empty when lo >= hi
+type rowSpan struct {
+	lo, hi int
+}
+
+// NewDiffRenderer creates a DiffRenderer writing to w
+func NewDiffRenderer(w io.Writer) *DiffRenderer {
+	return &DiffRenderer{w: w}
+}
+
+// BeginFrame starts a frame
+func (r *DiffRenderer) BeginFrame() error {
+	return nil
+}
+
+// SetCell records the cell, to be written at the end of the frame if the
+// screen doesn't already show it
+func (r *DiffRenderer) SetCell(x, y int, cell Cell) error {
+	if x < 0 || y < 0 {
+		return nil
+	}
+	r.next.SetCell(x, y, cell)
+	r.markDirty(y, x, x+1)
+	return nil
+}
+
+// Clear blanks the whole screen in the next frame. Only cells that aren't
+// drawn again in that frame are erased, so one tree can replace another
+// without the screen ever going blank.
+func (r *DiffRenderer) Clear() {
+	width, height := r.next.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r.next.SetCell(x, y, blankCell)
+		}
+		r.markDirty(y, 0, width)
+	}
+}
+
+// markDirty records that columns lo to hi of row y were set
+func (r *DiffRenderer) markDirty(y, lo, hi int) {
+	for len(r.dirty) <= y {
+		r.dirty = append(r.dirty, rowSpan{})
+	}
+	span := &r.dirty[y]
+	if span.lo >= span.hi {
+		*span = rowSpan{lo, hi}
+		return
+	}
+	span.lo, span.hi = min(span.lo, lo), max(span.hi, hi)
+}
+
+// EndFrame writes the cells that changed in one go
+func (r *DiffRenderer) EndFrame() error {
+	r.x, r.y = -1, -1
+	for y, span := range r.dirty {
+		for x := span.lo; x < span.hi; x++ {
+			cell := r.next.Cell(x, y)
+			if sameLook(cell, r.screen.Cell(x, y)) {
+				continue
+			}
+			r.screen.SetCell(x, y, cell)
+			if cell.Width == 0 {
+				continue // Covered by the wide glyph to its left
+			}
+			r.moveTo(x, y)
+			r.setStyle(cell)
+			r.buf.WriteString(cell.Glyph)
+			r.x += cell.Width
+		}
+		r.dirty[y] = rowSpan{}
+	}
+	r.setStyle(Cell{})
+
+	if r.buf.Len() == 0 {
+		return nil
+	}
+	if _, err := r.w.Write(r.buf.Bytes()); err != nil {
+		return err
+	}
+	r.buf.Reset()
+	return flush(r.w)
+}
+
+// moveTo puts the cursor at x, y, unless it is there already
+func (r *DiffRenderer) moveTo(x, y int) {
+	switch {
+	case r.x == x && r.y == y:
+		return
+	case r.y == y && r.x >= 0 && r.x < x:
+		fmt.Fprintf(&r.buf, "\033[%dC", x-r.x) // Forward along the row
+	default:
+		fmt.Fprintf(&r.buf, "\033[%d;%dH", y+1, x+1)
+	}
+	r.x, r.y = x, y
+}
+
+// setStyle switches to the colors and attributes of cell, if they differ
+// from the ones in effect
+func (r *DiffRenderer) setStyle(cell Cell) {
+	if sameStyle(cell, r.style) {
+		return
+	}
+	if r.style != (Cell{}) {
+		r.buf.WriteString(ColorReset)
+	}
+	r.style = Cell{Color: cell.Color, Background: cell.Background, Attrs: cell.Attrs}
+	r.buf.WriteString(cell.Attrs.escape() + cell.Color + cell.Background)
+}
+
+// sameStyle reports whether two cells have the same colors and attributes
+func sameStyle(a, b Cell) bool {
+	return a.Color == b.Color && a.Background == b.Background && a.Attrs == b.Attrs
+}
+
+// sameLook reports whether two cells look the same on screen
+func sameLook(a, b Cell) bool {
+	return a.Glyph == b.Glyph && a.Width == b.Width && sameStyle(a, b)
+}
@@ -0,0 +1,90 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiffRenderer(t *testing.T) {
+	plain := func(glyph string) Cell { return Cell{Glyph: glyph, Width: 1} }
+	red := func(glyph string) Cell { return Cell{Glyph: glyph, Width: 1, Color: ColorRed} }
+	type set struct {
+		x, y int
+		cell Cell
+	}
+	frames := []struct {
+		name  string
+		cells []set
+		want  string
+	}{
+		{"first", []set{{0, 0, plain("a")}, {1, 0, plain("b")}, {3, 1, plain("c")}}, "\033[1;1Hab\033[2;4Hc"},
+		{"unchanged", []set{{0, 0, plain("a")}, {1, 0, plain("b")}}, ""},
+		{"one change", []set{{0, 0, plain("a")}, {1, 0, plain("x")}}, "\033[1;2Hx"},
+		{"skip along a row", []set{{0, 0, plain("1")}, {3, 0, plain("4")}}, "\033[1;1H1\033[2C4"},
+		{"styled run", []set{{0, 2, red("r")}, {1, 2, red("s")}, {2, 2, plain("t")}}, "\033[3;1H" + ColorRed + "rs" + ColorReset + "t"},
+		{"restyled only", []set{{2, 2, red("t")}}, "\033[3;3H" + ColorRed + "t" + ColorReset},
+	}
+
+	var out bytes.Buffer
+	r := NewDiffRenderer(&out)
+	for _, f := range frames {
+		out.Reset()
+		if err := r.BeginFrame(); err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range f.cells {
+			if err := r.SetCell(s.x, s.y, s.cell); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.EndFrame(); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != f.want {
+			t.Errorf("%s frame wrote %q, want %q", f.name, got, f.want)
+		}
+	}
+}
+
+func TestDiffRendererClear(t *testing.T) {
+	var out bytes.Buffer
+	r := NewDiffRenderer(&out)
+	r.SetCell(0, 0, Cell{Glyph: "a", Width: 1})
+	r.SetCell(1, 0, Cell{Glyph: "b", Width: 1})
+	r.EndFrame()
+
+	// Only the cell that isn't drawn again is erased
+	out.Reset()
+	r.Clear()
+	r.SetCell(0, 0, Cell{Glyph: "a", Width: 1})
+	r.EndFrame()
+	if got, want := out.String(), "\033[1;2H "; got != want {
+		t.Errorf("clearing wrote %q, want %q", got, want)
+	}
+}
+
+func TestDiffRendererGrownTree(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	var out bytes.Buffer
+	r := NewDiffRenderer(&out)
+	if err := NewTree(config).GrowWith(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same tree drawn over it changes nothing on screen
+	out.Reset()
+	r.Clear()
+	tree := NewTree(config)
+	if err := tree.GrowWith(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.RenderWith(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("drawing the same tree again wrote %d bytes: %q", out.Len(), strings.ReplaceAll(out.String(), "\033", "^["))
+	}
+}
@@ -0,0 +1,166 @@
+package bonsai
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+)
+
+// GIFOptions controls how the growth of a tree is recorded as a GIF
+type GIFOptions struct {
+	ImageOptions
+	Every    int     // Record every Nth growth step, default 1
+	TimeStep float64 // Seconds per growth step, usually Config.TimeStep
+	Hold     float64 // Extra seconds to show the finished tree
+
+	// Size of the canvas in cells, usually what Tree.Size returns, so that
+	// frames line up with the PNG of the finished tree. When 0 the animation
+	// is as big as what was drawn.
+	Width, Height int
+}
+
+// GIFRecorder is a Renderer that records the frames of a growing tree and
+// encodes them as an animated GIF. Pass it to Tree.GrowWith, then call Encode.
+//
+// Only the part of the canvas that changed since the previous frame is
+// rasterized, so long animations stay small in memory and on disk.
+type GIFRecorder struct {
+	opts   GIFOptions
+	buf    Buffer
+	dirty  image.Rectangle // Cells drawn since the last recorded frame
+	steps  int
+	frames []*image.Paletted
+	delays []int
+}
+
+// NewGIFRecorder creates an empty GIFRecorder
+func NewGIFRecorder(opts GIFOptions) *GIFRecorder {
+	opts.ImageOptions = opts.ImageOptions.withDefaults()
+	if opts.Every <= 0 {
+		opts.Every = 1
+	}
+	return &GIFRecorder{opts: opts}
+}
+
+// BeginFrame starts a frame
+func (g *GIFRecorder) BeginFrame() error {
+	return nil
+}
+
+// SetCell records the cell and marks it as changed
+func (g *GIFRecorder) SetCell(x, y int, cell Cell) error {
+	if x < 0 || y < 0 {
+		return nil
+	}
+	g.dirty = g.dirty.Union(image.Rect(x, y, x+1, y+1))
+	return g.buf.SetCell(x, y, cell)
+}
+
+// EndFrame ends a growth step, capturing a frame every opts.Every steps
+func (g *GIFRecorder) EndFrame() error {
+	g.steps++
+	if g.steps%g.opts.Every == 0 {
+		g.capture()
+	}
+	return nil
+}
+
+// capture rasterizes the changed cells into a new frame
+func (g *GIFRecorder) capture() {
+	if g.dirty.Empty() {
+		return
+	}
+	o := g.opts.ImageOptions
+	px := image.Rect(
+		o.Padding+g.dirty.Min.X*o.CellWidth, o.Padding+g.dirty.Min.Y*o.CellHeight,
+		o.Padding+g.dirty.Max.X*o.CellWidth, o.Padding+g.dirty.Max.Y*o.CellHeight)
+	if len(g.frames) == 0 {
+		// The first frame covers the whole canvas, including the padding
+		width, height := g.size()
+		g.dirty = image.Rect(0, 0, width, height)
+		px = image.Rect(0, 0, width*o.CellWidth+2*o.Padding, height*o.CellHeight+2*o.Padding)
+	}
+
+	img := image.NewRGBA(px)
+	drawCells(img, &g.buf, g.dirty, o)
+	g.frames = append(g.frames, toPaletted(img))
+	g.delays = append(g.delays, gifDelay(g.opts.TimeStep*float64(g.opts.Every)))
+	g.dirty = image.Rectangle{}
+}
+
+// Encode writes the recorded animation to w
+func (g *GIFRecorder) Encode(w io.Writer) error {
+	g.capture() // Steps after the last recorded frame
+	if len(g.frames) == 0 {
+		// Nothing was drawn, so record a single blank cell
+		g.SetCell(0, 0, blankCell)
+		g.capture()
+	}
+
+	// Later frames may have grown the canvas, e.g. by drawing the message,
+	// so stretch the first frame over the full size with background
+	o := g.opts.ImageOptions
+	width, height := g.size()
+	bounds := image.Rect(0, 0, width*o.CellWidth+2*o.Padding, height*o.CellHeight+2*o.Padding)
+	if first := g.frames[0]; first.Rect != bounds {
+		img := image.NewRGBA(bounds)
+		draw.Draw(img, bounds, image.NewUniform(o.Background), image.Point{}, draw.Src)
+		draw.Draw(img, first.Rect, first, first.Rect.Min, draw.Src)
+		g.frames[0] = toPaletted(img)
+	}
+
+	delays := append([]int(nil), g.delays...)
+	delays[len(delays)-1] += gifDelay(g.opts.Hold)
+	disposal := make([]byte, len(g.frames))
+	for i := range disposal {
+		disposal[i] = gif.DisposalNone
+	}
+	return gif.EncodeAll(w, &gif.GIF{
+		Image:    g.frames,
+		Delay:    delays,
+		Disposal: disposal,
+		Config:   image.Config{Width: bounds.Dx(), Height: bounds.Dy()},
+	})
+}
+
+// size returns the size of the canvas in cells: the one given in the
+// options, grown to fit anything drawn outside it
+func (g *GIFRecorder) size() (int, int) {
+	width, height := g.buf.Size()
+	return max(width, g.opts.Width), max(height, g.opts.Height)
+}
+
+// gifDelay converts seconds to GIF delay units of 10ms. Browsers slow
+// anything below 2 units down to 10, so that is the shortest delay used.
+func gifDelay(seconds float64) int {
+	if seconds <= 0 {
+		return 0
+	}
+	return max(2, int(math.Round(seconds*100)))
+}
+
+// toPaletted converts img to a paletted image holding exactly its colors.
+// Trees only use a handful of colors, so this is lossless in practice.
+func toPaletted(img *image.RGBA) *image.Paletted {
+	var pal color.Palette
+	seen := make(map[color.RGBA]bool)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if !seen[c] {
+				seen[c] = true
+				pal = append(pal, c)
+			}
+		}
+	}
+	if len(pal) > 256 {
+		pal = palette.Plan9
+	}
+	p := image.NewPaletted(img.Rect, pal)
+	draw.Draw(p, p.Rect, img, img.Rect.Min, draw.Src)
+	return p
+}
@@ -0,0 +1,96 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/draw"
+	"image/gif"
+	"strconv"
+	"testing"
+)
+
+func TestGIFRecorderEmpty(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+	}{
+		{"no life", 80, 24},
+		{"single cell", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.LifeStart = 0
+			config.BaseType = 0
+			config.Width, config.Height = tt.width, tt.height
+			rec := NewGIFRecorder(GIFOptions{})
+			if err := NewTree(config).GrowWith(context.Background(), rec); err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := rec.Encode(&out); err != nil {
+				t.Fatal(err)
+			}
+			g, err := gif.DecodeAll(&out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Image) != 1 {
+				t.Errorf("got %d frames, want 1", len(g.Image))
+			}
+		})
+	}
+}
+
+func TestGIFRecorderEvery(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	all := NewGIFRecorder(GIFOptions{})
+	every := NewGIFRecorder(GIFOptions{Every: 10})
+	if err := NewTree(config).GrowWith(context.Background(), all); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewTree(config).GrowWith(context.Background(), every); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(every.frames), (len(all.frames)+9)/10; got < want-1 || got > want+1 {
+		t.Errorf("every 10th step gave %d frames out of %d", got, len(all.frames))
+	}
+}
+
+func TestGIFRecorderMatchesPNG(t *testing.T) {
+	for _, seed := range []int64{1, 3} {
+		config := DefaultConfig()
+		config.Seed = seed
+		config.Message = "grown from seed " + strconv.FormatInt(seed, 10)
+		config.MessagePos = MessageBelow
+		tree := NewTree(config)
+		width, height := tree.Size()
+		rec := NewGIFRecorder(GIFOptions{ImageOptions: ImageOptions{Padding: 4}, Width: width, Height: height})
+		if err := tree.GrowWith(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := rec.Encode(&out); err != nil {
+			t.Fatal(err)
+		}
+		g, err := gif.DecodeAll(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Played back, the frames add up to the PNG of the finished tree
+		want := DrawImage(tree.Snapshot(), ImageOptions{Padding: 4})
+		if g.Config.Width != want.Rect.Dx() || g.Config.Height != want.Rect.Dy() {
+			t.Fatalf("seed %d: GIF is %dx%d, PNG is %dx%d", seed, g.Config.Width, g.Config.Height, want.Rect.Dx(), want.Rect.Dy())
+		}
+		got := image.NewRGBA(want.Rect)
+		for _, frame := range g.Image {
+			draw.Draw(got, frame.Rect, frame, frame.Rect.Min, draw.Over)
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("seed %d: last GIF frame differs from the PNG", seed)
+		}
+	}
+}
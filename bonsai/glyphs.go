@@ -0,0 +1,225 @@
+package bonsai
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Strokes are the glyphs a branch is drawn with, by the direction it grows
+// in. Each is a glyph list like --leaf takes, "|:3,‖" for example, and an
+// empty one keeps the default.
+type Strokes struct {
+	Flat      string `json:"flat,omitempty"`      // Growing sideways
+	UpLeft    string `json:"upLeft,omitempty"`    // Growing up and to the left
+	Up        string `json:"up,omitempty"`        // Growing straight up
+	UpRight   string `json:"upRight,omitempty"`   // Growing up and to the right
+	DownLeft  string `json:"downLeft,omitempty"`  // Left shoots growing down
+	DownRight string `json:"downRight,omitempty"` // Right shoots growing down
+}
+
+// strokeNames maps the names used by ParseStrokes to their fields
+func (s *Strokes) strokeNames() map[string]*string {
+	return map[string]*string{
+		"flat":      &s.Flat,
+		"upleft":    &s.UpLeft,
+		"up":        &s.Up,
+		"upright":   &s.UpRight,
+		"downleft":  &s.DownLeft,
+		"downright": &s.DownRight,
+	}
+}
+
+// ParseStrokes parses space-separated direction=glyphs pairs, such as
+// "up=|:3,‖ flat=~". Directions are flat, upleft, up, upright, downleft
+// and downright; directions left out keep their default.
+func ParseStrokes(s string) (Strokes, error) {
+	var strokes Strokes
+	names := strokes.strokeNames()
+	for _, pair := range strings.Fields(s) {
+		name, spec, ok := strings.Cut(pair, "=")
+		field, known := names[strings.ToLower(name)]
+		if !ok || !known {
+			return Strokes{}, fmt.Errorf("%q is not direction=glyphs, directions are flat, upleft, up, upright, downleft and downright", pair)
+		}
+		*field = spec
+	}
+	return strokes, strokes.validate()
+}
+
+// validate checks every glyph list that is set
+func (s Strokes) validate() error {
+	for name, field := range s.strokeNames() {
+		if *field == "" {
+			continue
+		}
+		if _, err := parseGlyphSet("glyph", strings.Split(*field, ",")); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// weightedSet is a list of glyphs or colors to pick from, each with a weight
+type weightedSet struct {
+	items   []string
+	weights []int
+	total   int
+}
+
+// parseWeighted parses entries of the form item or item:weight, passing
+// every item through check. Weights default to 1, and a colon that isn't
+// followed by a number is part of the item, so ":" and "a:b" are glyphs
+// too. Errors call an entry what.
+func parseWeighted(what string, entries []string, check func(string) (string, error)) (weightedSet, error) {
+	var set weightedSet
+	for i, entry := range entries {
+		item, weight := entry, 1
+		if at := strings.LastIndex(entry, ":"); at > 0 {
+			if w, err := strconv.Atoi(entry[at+1:]); err == nil {
+				if w < 0 {
+					return weightedSet{}, fmt.Errorf("%s %d has a negative weight", what, i+1)
+				}
+				item, weight = entry[:at], w
+			}
+		}
+		item, err := check(item)
+		if err != nil {
+			return weightedSet{}, fmt.Errorf("%s %d %w", what, i+1, err)
+		}
+		set.items = append(set.items, item)
+		set.weights = append(set.weights, weight)
+		set.total += weight
+	}
+	if len(entries) > 0 && set.total == 0 {
+		return weightedSet{}, fmt.Errorf("every %s has a weight of 0", what)
+	}
+	return set, nil
+}
+
+// parseGlyphSet parses weighted glyphs
+func parseGlyphSet(what string, entries []string) (weightedSet, error) {
+	return parseWeighted(what, entries, func(glyph string) (string, error) {
+		return glyph, validateGlyph(glyph)
+	})
+}
+
+// validateGlyph checks that a glyph can be drawn
+func validateGlyph(glyph string) error {
+	switch width := StringWidth(glyph); {
+	case glyph == "":
+		return fmt.Errorf("is empty")
+	case !utf8.ValidString(glyph):
+		return fmt.Errorf("is not valid UTF-8")
+	case strings.ContainsFunc(glyph, unicode.IsControl):
+		return fmt.Errorf("contains control characters")
+	case width == 0:
+		return fmt.Errorf("has nothing visible")
+	case width > maxGlyphWidth:
+		return fmt.Errorf("is %d columns wide, at most %d are allowed", width, maxGlyphWidth)
+	}
+	return nil
+}
+
+// single is a set of one item
+func single(item string) weightedSet {
+	return weightedSet{items: []string{item}, weights: []int{1}, total: 1}
+}
+
+// glyphSetOr parses entries, falling back to def when there are none or
+// they don't parse. Config.Validate reports the errors.
+func glyphSetOr(entries []string, def string) weightedSet {
+	if set, err := parseGlyphSet("glyph", entries); err == nil && len(set.items) > 0 {
+		return set
+	}
+	return single(def)
+}
+
+// strokeOr parses a comma-delimited stroke glyph list, or falls back to def
+func strokeOr(spec, def string) weightedSet {
+	if spec == "" {
+		return single(def)
+	}
+	return glyphSetOr(strings.Split(spec, ","), def)
+}
+
+// roll picks a weighted item. It always draws from rng, even for a single
+// item, which is how leaves have always been picked.
+func (w weightedSet) roll(rng *rand.Rand) string {
+	n := rng.Intn(w.total)
+	for i, weight := range w.weights {
+		if n < weight {
+			return w.items[i]
+		}
+		n -= weight
+	}
+	return w.items[len(w.items)-1]
+}
+
+// leaf rolls a leaf, or gives the classic "&" without rolling when there
+// are no leaves at all
+func (w weightedSet) leaf(rng *rand.Rand) string {
+	if len(w.items) == 0 {
+		return "&"
+	}
+	return w.roll(rng)
+}
+
+// pick is roll for the items the classic tree never rolled for, such as
+// strokes, which only draws from rng when there is a choice. An empty set
+// picks "".
+func (w weightedSet) pick(rng *rand.Rand) string {
+	switch len(w.items) {
+	case 0:
+		return ""
+	case 1:
+		return w.items[0]
+	}
+	return w.roll(rng)
+}
+
+// strokeSet is Strokes parsed and with defaults filled in
+type strokeSet struct {
+	flat, upLeft, up, upRight, downLeft, downRight weightedSet
+}
+
+// glyphTable holds every glyph set a tree draws with
+type glyphTable struct {
+	trunk, shoot strokeSet
+	dying, dead  weightedSet
+}
+
+// newGlyphTable builds the glyph sets from config, falling back to the
+// classic cbonsai characters for anything left out
+func newGlyphTable(config *Config) *glyphTable {
+	strokes := func(s Strokes, flat string) strokeSet {
+		return strokeSet{
+			flat:      strokeOr(s.Flat, flat),
+			upLeft:    strokeOr(s.UpLeft, "\\"),
+			up:        strokeOr(s.Up, "|"),
+			upRight:   strokeOr(s.UpRight, "/"),
+			downLeft:  strokeOr(s.DownLeft, "\\"),
+			downRight: strokeOr(s.DownRight, "/"),
+		}
+	}
+	var leaves weightedSet
+	if len(config.Leaves) > 0 {
+		leaves = glyphSetOr(config.Leaves, "&")
+	}
+	dying, dead := leaves, leaves
+	if len(config.DyingLeaves) > 0 {
+		dying = glyphSetOr(config.DyingLeaves, "&")
+	}
+	if len(config.DeadLeaves) > 0 {
+		dead = glyphSetOr(config.DeadLeaves, "&")
+	}
+	return &glyphTable{
+		trunk: strokes(config.TrunkGlyphs, "~"),
+		shoot: strokes(config.ShootGlyphs, "_"),
+		dying: dying,
+		dead:  dead,
+	}
+}
@@ -0,0 +1,94 @@
+package bonsai
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGlyphSet(t *testing.T) {
+	tests := []struct {
+		entries []string
+		items   []string
+		weights []int
+	}{
+		{[]string{"&", "*"}, []string{"&", "*"}, []int{1, 1}},
+		{[]string{"&:5", "*:2", "@"}, []string{"&", "*", "@"}, []int{5, 2, 1}},
+		{[]string{"&:x"}, []string{"&:x"}, []int{1}},            // Not a weight
+		{[]string{"a:b:3"}, []string{"a:b"}, []int{3}},          // Only the last colon counts
+		{[]string{":", "*:0"}, []string{":", "*"}, []int{1, 0}}, // A lone colon is a glyph
+	}
+	for _, tt := range tests {
+		set, err := parseGlyphSet("leaf", tt.entries)
+		if err != nil {
+			t.Errorf("parseGlyphSet(%q): %v", tt.entries, err)
+			continue
+		}
+		if !reflect.DeepEqual(set.items, tt.items) || !reflect.DeepEqual(set.weights, tt.weights) {
+			t.Errorf("parseGlyphSet(%q) = %q %v, want %q %v", tt.entries, set.items, set.weights, tt.items, tt.weights)
+		}
+	}
+}
+
+func TestWeightedRoll(t *testing.T) {
+	set, err := parseGlyphSet("leaf", []string{"a:3", "b:1", "c:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	for range 4000 {
+		counts[set.roll(rng)]++
+	}
+	if counts["c"] != 0 {
+		t.Errorf("a glyph of weight 0 was rolled %d times", counts["c"])
+	}
+	if ratio := float64(counts["a"]) / float64(counts["b"]); ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("weights 3:1 rolled %d:%d", counts["a"], counts["b"])
+	}
+}
+
+func TestParseStrokes(t *testing.T) {
+	strokes, err := ParseStrokes("up=|:3,‖ flat=~")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strokes.Up != "|:3,‖" || strokes.Flat != "~" || strokes.UpLeft != "" {
+		t.Errorf("ParseStrokes = %+v", strokes)
+	}
+	for _, bad := range []string{"sideways=|", "up", "up=|,"} {
+		if _, err := ParseStrokes(bad); err == nil {
+			t.Errorf("ParseStrokes(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCustomGlyphs(t *testing.T) {
+	all := func(glyph string) Strokes {
+		return Strokes{Flat: glyph, UpLeft: glyph, Up: glyph, UpRight: glyph, DownLeft: glyph, DownRight: glyph}
+	}
+	config := DefaultConfig()
+	config.Seed = 3
+	config.BaseType = 0
+	config.TrunkGlyphs = all("T")
+	config.ShootGlyphs = all("S")
+	config.Leaves = []string{"L"}
+	tree := NewTree(config)
+	if err := tree.Grow(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[rune]int)
+	for _, r := range tree.Snapshot().String() {
+		counts[r]++
+	}
+	for r := range counts {
+		if !strings.ContainsRune("TSL \n", r) {
+			t.Errorf("tree has a %q in it", r)
+		}
+	}
+	if counts['T'] == 0 || counts['S'] == 0 || counts['L'] == 0 {
+		t.Errorf("tree doesn't use every glyph set: %v", counts)
+	}
+}
@@ -0,0 +1,234 @@
+package bonsai
+
+import (
+	"context"
+	"math/rand"
+)
+
+// The tree grows on an explicit stack of branches rather than by recursion.
+// The branch on top grows one step at a time; when a step sprouts a new
+// branch, the step waits on the stack until the new branch and everything
+// growing from it is done, and only then moves and draws. That is exactly
+// the order the recursive version drew in, so a seed grows the same tree.
+
+// branchFrame is a branch on the growth stack
+type branchFrame struct {
+	id            int // Scene ID
+	parent        int // Scene ID of the branch it grew from, -1 for the trunk
+	x, y          int
+	branchType    BranchType
+	life          int
+	shootCooldown int
+
+	// A step that sprouted a branch, waiting for it to finish
+	waiting     bool
+	dx, dy, age int
+}
+
+// PendingBranch is a branch that hasn't finished growing
+type PendingBranch struct {
+	ID   int        // ID of the branch in the Scene
+	Type BranchType // Kind of branch
+	At   Point      // Where its next step grows from
+	Life int        // Steps it has left
+}
+
+// DrawnCell is a cell drawn on the canvas during a step
+type DrawnCell struct {
+	X, Y int
+	Cell Cell
+}
+
+// Start gets the tree ready to grow a step at a time with Step: it clears
+// the canvas, draws the pot and plants the trunk. Everything drawn is also
+// sent to r, which may be nil, with every step as its own frame.
+func (bt *Tree) Start(ctx context.Context, r Renderer) error {
+	bt.start(ctx, r, false)
+	return bt.err
+}
+
+// Step grows the tree by one step and returns the cells it drew. Once no
+// branch is left growing, it draws the message and reports false; after
+// that it does nothing. Check Err when it is done.
+func (bt *Tree) Step() ([]DrawnCell, bool) {
+	if !bt.growing {
+		return nil, false
+	}
+	bt.drawn, bt.capture = nil, true
+	defer func() { bt.capture = false }()
+	if bt.advance(0) {
+		return bt.drawn, true
+	}
+	bt.finish()
+	return bt.drawn, false
+}
+
+// Err returns the error that stopped growth, if any
+func (bt *Tree) Err() error {
+	return bt.err
+}
+
+// Steps returns how many steps have been drawn so far
+func (bt *Tree) Steps() int {
+	return bt.steps
+}
+
+// Pending returns the branches still growing, from the trunk up to the one
+// whose step comes next
+func (bt *Tree) Pending() []PendingBranch {
+	pending := make([]PendingBranch, 0, len(bt.stack))
+	for _, b := range bt.stack {
+		pending = append(pending, PendingBranch{ID: b.id, Type: b.branchType, At: Point{b.x, b.y}, Life: b.life})
+	}
+	return pending
+}
+
+// looksSeed is mixed into the seed of the looks source, so that it doesn't
+// roll the same numbers as the growth rules
+const looksSeed = 0x6c6f6f6b73
+
+// start resets the tree, draws the pot and plants the trunk
+func (bt *Tree) start(ctx context.Context, r Renderer, paced bool) {
+	bt.branches = 0
+	bt.shoots = 0
+	bt.steps = 0
+	bt.stack = bt.stack[:0]
+	bt.rng = rand.New(rand.NewSource(bt.config.Seed))
+	bt.looks = rand.New(rand.NewSource(bt.config.Seed ^ looksSeed))
+	bt.resetScene()
+	bt.ctx = ctx
+	bt.renderer = r
+	bt.paced = paced
+	bt.err = nil
+	bt.growing = true
+
+	// Clear canvas
+	for i := range bt.canvas {
+		for j := range bt.canvas[i] {
+			bt.canvas[i][j] = blankCell
+		}
+	}
+	bt.resetDots()
+
+	if r != nil {
+		bt.err = r.BeginFrame()
+	}
+
+	bt.layout = bt.layoutMessage()
+	bt.glyphs = newGlyphTable(bt.config)
+	bt.palette = newPaletteTable(bt.config)
+	bt.DrawBase()
+	bt.endFrame()
+
+	startX := bt.layout.centerX
+	startY := bt.config.Height + 2
+	if pot, ok := bt.pot(); ok {
+		startY -= len(pot.Lines) + 1 // Account for base height + grass line above the pot
+	}
+	bt.push(startX*bt.scale(), startY*bt.scale(), Trunk, bt.config.LifeStart)
+}
+
+// finish draws the message over the finished tree and closes the last frame
+func (bt *Tree) finish() {
+	// The message goes on top of anything the branches drew
+	bt.drawMessageBox()
+	if bt.renderer != nil && bt.err == nil {
+		bt.err = bt.drawMessageBelow(bt.renderer)
+	}
+	if bt.renderer != nil && bt.err == nil {
+		bt.err = bt.renderer.EndFrame()
+	}
+	bt.ctx = nil
+	bt.renderer = nil
+	bt.growing = false
+}
+
+// push starts a new branch growing from the current one
+func (bt *Tree) push(x, y int, branchType BranchType, life int) {
+	parent := bt.beginBranch(bt.branches, x, y, branchType, life)
+	bt.branches++
+	bt.stack = append(bt.stack, branchFrame{
+		id:            bt.current,
+		parent:        parent,
+		x:             x,
+		y:             y,
+		branchType:    branchType,
+		life:          life,
+		shootCooldown: bt.config.Multiplier,
+	})
+}
+
+// advance grows the branches above the bottom floor of the stack until one
+// step is drawn. It reports false once they have all finished, or growth
+// stopped because of an error or the context being cancelled.
+func (bt *Tree) advance(floor int) bool {
+	for len(bt.stack) > floor && bt.err == nil {
+		if err := bt.ctx.Err(); err != nil {
+			bt.err = err
+			return false
+		}
+		b := &bt.stack[len(bt.stack)-1]
+		bt.current = b.id
+
+		if b.waiting {
+			b.waiting = false
+			bt.drawStep(b)
+			return true
+		}
+		if b.life <= 0 {
+			bt.current = b.parent
+			bt.stack = bt.stack[:len(bt.stack)-1]
+			continue
+		}
+
+		b.life--
+		b.age = bt.config.LifeStart - b.life
+		b.dx, b.dy = bt.GetDeltas(b.branchType, b.life, b.age)
+
+		// Prevent going too close to ground
+		if b.dy > 0 && b.y > bt.scale()*(bt.config.Height-7) {
+			b.dy--
+		}
+		// Ensure first move is at least 1 up
+		if b.age == 1 && b.dy >= 0 {
+			b.dy = -2
+		}
+
+		branchType, life, sprouted := bt.rules.sprout(bt, b)
+		b.shootCooldown--
+		if sprouted {
+			// The step is drawn once the new branch is done growing
+			b.waiting = true
+			bt.push(b.x, b.y, branchType, life)
+			continue
+		}
+		bt.drawStep(b)
+		return true
+	}
+	return false
+}
+
+// drawStep moves b by its step and draws it
+func (bt *Tree) drawStep(b *branchFrame) {
+	b.x += b.dx
+	b.y += b.dy
+	bt.steps++
+
+	char := bt.ChooseChar(b.branchType, b.life, b.dx, b.dy)
+	color := bt.GetBranchColor(b.branchType)
+	leaf := b.life < 4 || b.branchType == Dying || b.branchType == Dead
+	color = bt.shade(color, b.branchType, leaf, b.age, b.y)
+	style := bt.stepStyle(b.branchType, leaf, color)
+	if bt.plotted() {
+		bt.plotStep(b.x-b.dx, b.y-b.dy, b.x, b.y, b.branchType, leaf, style)
+	} else {
+		bt.draw(b.x, b.y, char, style)
+	}
+	bt.recordStep(b.x, b.y, leaf, char, color)
+
+	// Each step is its own frame, paced in live mode
+	bt.endFrame()
+	if bt.paced {
+		bt.sleep()
+	}
+}
@@ -0,0 +1,176 @@
+package bonsai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// cellLog is a Renderer that keeps every cell drawn, in order
+type cellLog struct {
+	cells []DrawnCell
+}
+
+func (l *cellLog) BeginFrame() error { return nil }
+func (l *cellLog) EndFrame() error   { return nil }
+
+func (l *cellLog) SetCell(x, y int, cell Cell) error {
+	l.cells = append(l.cells, DrawnCell{x, y, cell})
+	return nil
+}
+
+// growConfigs are configs the growth tests run against
+func growConfigs() map[string]*Config {
+	configs := make(map[string]*Config)
+	for _, style := range []DrawStyle{StyleText, StyleBraille, StylePixels} {
+		config := DefaultConfig()
+		config.Seed = 7
+		config.Style = style
+		config.Message = "a message in a box"
+		configs[string(style)] = config
+	}
+	big := DefaultConfig()
+	big.Seed = 3
+	big.LifeStart = 200
+	big.Multiplier = 12
+	configs["big"] = big
+	return configs
+}
+
+func TestStepMatchesGrow(t *testing.T) {
+	for name, config := range growConfigs() {
+		t.Run(name, func(t *testing.T) {
+			var grown cellLog
+			if err := NewTree(config).GrowWith(context.Background(), &grown); err != nil {
+				t.Fatal(err)
+			}
+
+			tree := NewTree(config)
+			var stepped cellLog
+			if err := tree.Start(context.Background(), &stepped); err != nil {
+				t.Fatal(err)
+			}
+			base := len(stepped.cells)
+			var fromSteps []DrawnCell
+			steps := 0
+			for {
+				cells, more := tree.Step()
+				fromSteps = append(fromSteps, cells...)
+				if !more {
+					break
+				}
+				steps++
+				if tree.Steps() != steps {
+					t.Fatalf("Steps() = %d after %d steps", tree.Steps(), steps)
+				}
+			}
+			if err := tree.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(tree.Pending()) != 0 {
+				t.Errorf("%d branches still pending after growing", len(tree.Pending()))
+			}
+			if !reflect.DeepEqual(stepped.cells, grown.cells) {
+				t.Errorf("stepping drew %d cells, growing drew %d", len(stepped.cells), len(grown.cells))
+			}
+			if !reflect.DeepEqual(fromSteps, grown.cells[base:]) {
+				t.Errorf("Step returned %d cells, growing drew %d after the pot", len(fromSteps), len(grown.cells)-base)
+			}
+			if _, more := tree.Step(); more {
+				t.Error("Step kept going after the tree was done")
+			}
+		})
+	}
+}
+
+func TestPendingStartsAtTrunk(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	tree := NewTree(config)
+	if err := tree.Start(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	pending := tree.Pending()
+	if len(pending) != 1 || pending[0].Type != Trunk || pending[0].Life != config.LifeStart {
+		t.Fatalf("pending before the first step: %+v", pending)
+	}
+	for range 20 {
+		tree.Step()
+	}
+	pending = tree.Pending()
+	if len(pending) == 0 || pending[0].Type != Trunk {
+		t.Errorf("pending after 20 steps doesn't start at the trunk: %+v", pending)
+	}
+}
+
+func TestGrowAgain(t *testing.T) {
+	for name, config := range growConfigs() {
+		t.Run(name, func(t *testing.T) {
+			tree := NewTree(config)
+			if err := tree.GrowWith(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+			first := tree.Snapshot().String()
+			if err := tree.GrowWith(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+			if again := tree.Snapshot().String(); again != first {
+				t.Errorf("growing again gave another tree:\n%s\nwant:\n%s", again, first)
+			}
+		})
+	}
+}
+
+func TestGrowCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config := DefaultConfig()
+	config.Seed = 1
+	var log cellLog
+	if err := NewTree(config).GrowWith(ctx, &log); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GrowWith() = %v, want %v", err, context.Canceled)
+	}
+
+	// Cancelling part way stops the steps
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	tree := NewTree(config)
+	if err := tree.Start(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		if _, more := tree.Step(); !more {
+			t.Fatal("tree finished within 10 steps")
+		}
+	}
+	cancel()
+	if _, more := tree.Step(); more {
+		t.Error("Step went on after the context was cancelled")
+	}
+	if !errors.Is(tree.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, want %v", tree.Err(), context.Canceled)
+	}
+	if tree.Steps() != 10 {
+		t.Errorf("%d steps drawn, want 10", tree.Steps())
+	}
+}
+
+func TestLooksSeed(t *testing.T) {
+	// Looks don't roll the same numbers as the growth rules
+	config := DefaultConfig()
+	config.Seed = 1
+	tree := NewTree(config)
+	if err := tree.Start(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	same := 0
+	for range 20 {
+		if tree.rng.Intn(10) == tree.looks.Intn(10) {
+			same++
+		}
+	}
+	if same == 20 {
+		t.Error("looks and growth are rolled from the same seed")
+	}
+}
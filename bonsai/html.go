@@ -0,0 +1,194 @@
+package bonsai
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HTMLOptions controls the HTML page a tree is written to
+type HTMLOptions struct {
+	Title      string  // Page title, default "bonsai"
+	Background string  // CSS background color, default black
+	Foreground string  // Color for cells without one, default #e5e5e5
+	TimeStep   float64 // Animated pages: seconds per growth step
+}
+
+func (o HTMLOptions) withDefaults() HTMLOptions {
+	if o.Title == "" {
+		o.Title = "bonsai"
+	}
+	o.Background = cssColor(o.Background, "#000000")
+	o.Foreground = cssColor(o.Foreground, HexColor(ansiBasic[7]))
+	return o
+}
+
+// cssColor returns c as it goes in the style sheet: hex colors as #rrggbb
+// and color names as they are. Anything else, which could break out of the
+// style sheet, gives def.
+func cssColor(c, def string) string {
+	if rgb, err := ParseHexColor(c); err == nil {
+		return HexColor(rgb)
+	}
+	if c == "" || strings.TrimFunc(c, func(r rune) bool {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+	}) != "" {
+		return def
+	}
+	return c
+}
+
+// WriteHTML writes the contents of b as a self-contained HTML page: a single
+// <pre> block with a colored span per run of cells of the same color
+func WriteHTML(w io.Writer, b *Buffer, opts HTMLOptions) error {
+	return writeHTML(w, b, nil, opts)
+}
+
+// HTMLRecorder is a Renderer that remembers the growth step at which every
+// cell was first drawn. Pass it to Tree.GrowWith, then call Encode to write
+// a page where CSS animation reveals the cells in that order, without any
+// JavaScript.
+type HTMLRecorder struct {
+	opts  HTMLOptions
+	buf   Buffer
+	steps int
+	first map[image.Point]int
+}
+
+// NewHTMLRecorder creates an empty HTMLRecorder
+func NewHTMLRecorder(opts HTMLOptions) *HTMLRecorder {
+	return &HTMLRecorder{opts: opts, first: make(map[image.Point]int)}
+}
+
+// BeginFrame starts a growth step
+func (h *HTMLRecorder) BeginFrame() error {
+	return nil
+}
+
+// SetCell records the cell and the step it first appeared in
+func (h *HTMLRecorder) SetCell(x, y int, cell Cell) error {
+	p := image.Pt(x, y)
+	if _, ok := h.first[p]; !ok && !cell.Blank() {
+		h.first[p] = h.steps
+	}
+	return h.buf.SetCell(x, y, cell)
+}
+
+// EndFrame ends a growth step
+func (h *HTMLRecorder) EndFrame() error {
+	h.steps++
+	return nil
+}
+
+// Encode writes the animated page
+func (h *HTMLRecorder) Encode(w io.Writer) error {
+	return writeHTML(w, &h.buf, h.first, h.opts)
+}
+
+// writeHTML writes the page for b. When first is set every glyph becomes
+// its own span, revealed after the step it was first drawn in.
+func writeHTML(w io.Writer, b *Buffer, first map[image.Point]int, opts HTMLOptions) error {
+	opts = opts.withDefaults()
+	width, height := b.Size()
+
+	// One class per style in use, in order of appearance
+	classes := make(map[string]string)
+	var css strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := b.Cell(x, y)
+			key := htmlStyleKey(cell)
+			if _, ok := classes[key]; ok {
+				continue
+			}
+			var rules []string
+			if c, ok := ANSIToRGB(cell.Color); ok {
+				rules = append(rules, "color:"+HexColor(c))
+			}
+			if c, ok := ANSIToRGB(cell.Background); ok {
+				rules = append(rules, "background:"+HexColor(c))
+			}
+			if cell.Attrs&AttrBold != 0 {
+				rules = append(rules, "font-weight:bold")
+			}
+			if cell.Attrs&AttrDim != 0 {
+				rules = append(rules, "opacity:0.5")
+			}
+			if cell.Attrs&AttrItalic != 0 {
+				rules = append(rules, "font-style:italic")
+			}
+			style := strings.Join(rules, ";")
+			if style != "" {
+				classes[key] = "c" + strconv.Itoa(len(classes))
+				fmt.Fprintf(&css, ".bonsai .%s{%s}\n", classes[key], style)
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n", html.EscapeString(opts.Title))
+	fmt.Fprintf(bw, "body{margin:0;background:%s}\n", opts.Background)
+	fmt.Fprintf(bw, ".bonsai{margin:0;padding:1em;line-height:1.2;color:%s;background:%s;font-family:'DejaVu Sans Mono',Menlo,Consolas,monospace}\n", opts.Foreground, opts.Background)
+	if first != nil {
+		bw.WriteString(".bonsai .g{opacity:0;animation:bonsai-grow 0s forwards}\n")
+		bw.WriteString("@keyframes bonsai-grow{to{opacity:1}}\n")
+	}
+	bw.WriteString(css.String())
+	bw.WriteString("</style>\n</head>\n<body>\n<pre class=\"bonsai\">")
+
+	for y := 0; y < height; y++ {
+		open := "" // Color of the span currently open in static mode
+		for x := 0; x < width; x++ {
+			cell := b.Cell(x, y)
+			if cell.Width == 0 {
+				continue // Covered by the wide glyph to the left
+			}
+			text := html.EscapeString(cell.Glyph)
+			class, colored := classes[htmlStyleKey(cell)]
+
+			if first != nil {
+				step, drawn := first[image.Pt(x, y)]
+				if !drawn {
+					bw.WriteString(text)
+					continue
+				}
+				if colored {
+					class = "g " + class
+				} else {
+					class = "g"
+				}
+				delay := strconv.FormatFloat(math.Round(float64(step)*opts.TimeStep*1000)/1000, 'f', -1, 64)
+				fmt.Fprintf(bw, "<span class=\"%s\" style=\"animation-delay:%ss\">%s</span>", class, delay, text)
+				continue
+			}
+
+			// Spaces never need a color, so they don't break up a run
+			if !cell.Blank() && class != open {
+				if open != "" {
+					bw.WriteString("</span>")
+				}
+				if colored {
+					fmt.Fprintf(bw, "<span class=\"%s\">", class)
+				}
+				open = class
+			}
+			bw.WriteString(text)
+		}
+		if open != "" {
+			bw.WriteString("</span>")
+		}
+		bw.WriteByte('\n')
+	}
+	bw.WriteString("</pre>\n</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// htmlStyleKey identifies the look of a cell, which gets its own CSS class
+func htmlStyleKey(cell Cell) string {
+	return cell.Color + cell.Background + string(rune('0'+cell.Attrs))
+}
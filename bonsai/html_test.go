@@ -0,0 +1,80 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var b Buffer
+	b.SetCell(0, 0, Cell{Glyph: "&", Width: 1, Color: ColorRed})
+	b.SetCell(1, 0, Cell{Glyph: "*", Width: 1, Color: ColorRed})
+	b.SetCell(2, 0, Cell{Glyph: "<", Width: 1})
+
+	var out bytes.Buffer
+	if err := WriteHTML(&out, &b, HTMLOptions{Title: "a <tree>"}); err != nil {
+		t.Fatal(err)
+	}
+	page := out.String()
+	for _, want := range []string{
+		"<title>a &lt;tree&gt;</title>",
+		".bonsai .c0{color:#cd0000}",
+		`<span class="c0">&amp;*</span>&lt;`, // A run of one color shares a span
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %s:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "animation") {
+		t.Error("static page is animated")
+	}
+}
+
+func TestHTMLColors(t *testing.T) {
+	tests := []struct {
+		background, want string
+	}{
+		{"", "background:#000000"},
+		{"fff", "background:#ffffff"},
+		{"#1E1E2E", "background:#1e1e2e"},
+		{"navy", "background:navy"},
+		{"red}</style><script>alert(1)</script>", "background:#000000"},
+	}
+	var b Buffer
+	b.SetCell(0, 0, Cell{Glyph: "&", Width: 1})
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := WriteHTML(&out, &b, HTMLOptions{Background: tt.background}); err != nil {
+			t.Fatal(err)
+		}
+		page := out.String()
+		if !strings.Contains(page, "body{margin:0;"+tt.want+"}") {
+			t.Errorf("background %q: page lacks %s:\n%s", tt.background, tt.want, page)
+		}
+		if strings.Contains(page, "<script>") {
+			t.Errorf("background %q got into the page", tt.background)
+		}
+	}
+}
+
+func TestHTMLRecorder(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	config.TimeStep = 0.5
+	rec := NewHTMLRecorder(HTMLOptions{TimeStep: config.TimeStep})
+	if err := NewTree(config).GrowWith(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := rec.Encode(&out); err != nil {
+		t.Fatal(err)
+	}
+	page := out.String()
+	for _, want := range []string{"@keyframes bonsai-grow", `style="animation-delay:0s"`, `style="animation-delay:0.5s"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("animated page lacks %s", want)
+		}
+	}
+}
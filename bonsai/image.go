@@ -0,0 +1,153 @@
+package bonsai
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ImageOptions controls how a tree is rasterized. Zero values fall back to
+// the defaults below.
+type ImageOptions struct {
+	CellWidth  int         // Width of one cell in px, default the font width
+	CellHeight int         // Height of one cell in px, default the font height
+	Padding    int         // Empty border around the canvas in px
+	Background color.Color // Default black
+	Foreground color.Color // Color for cells without one, default ANSI white
+}
+
+func (o ImageOptions) withDefaults() ImageOptions {
+	if o.CellWidth <= 0 {
+		o.CellWidth = fontWidth
+	}
+	if o.CellHeight <= 0 {
+		o.CellHeight = fontHeight
+	}
+	if o.Padding < 0 {
+		o.Padding = 0
+	}
+	if o.Background == nil {
+		o.Background = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	}
+	if o.Foreground == nil {
+		o.Foreground = ansiBasic[7]
+	}
+	return o
+}
+
+// DrawImage rasterizes the contents of b with the built-in bitmap font.
+// Glyphs are scaled up by whole pixels to fit the cell and centered in it.
+func DrawImage(b *Buffer, opts ImageOptions) *image.RGBA {
+	opts = opts.withDefaults()
+	width, height := b.Size()
+	img := image.NewRGBA(image.Rect(0, 0,
+		width*opts.CellWidth+2*opts.Padding,
+		height*opts.CellHeight+2*opts.Padding))
+	drawCells(img, b, image.Rect(0, 0, width, height), opts)
+	return img
+}
+
+// drawCells paints the background of img and then every cell of b inside
+// cells, a rectangle in cell coordinates. opts must have its defaults set.
+func drawCells(img *image.RGBA, b *Buffer, cells image.Rectangle, opts ImageOptions) {
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	scale := max(1, min(opts.CellWidth/fontWidth, opts.CellHeight/fontHeight))
+	offX := (opts.CellWidth - fontWidth*scale) / 2
+	offY := (opts.CellHeight - fontHeight*scale) / 2
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			cell := b.Cell(x, y)
+			if cell.Blank() {
+				continue
+			}
+			fg := color.RGBAModel.Convert(opts.Foreground).(color.RGBA)
+			if c, ok := ANSIToRGB(cell.Color); ok {
+				fg = c
+			}
+			r := image.Rect(0, 0, opts.CellWidth, opts.CellHeight).
+				Add(image.Pt(opts.Padding+x*opts.CellWidth, opts.Padding+y*opts.CellHeight))
+			bg := color.RGBAModel.Convert(opts.Background).(color.RGBA)
+			if c, ok := ANSIToRGB(cell.Background); ok {
+				bg = c
+				draw.Draw(img, r, image.NewUniform(bg), image.Point{}, draw.Src)
+			}
+			if cell.Attrs&AttrDim != 0 {
+				fg = blend(fg, bg)
+			}
+			if top, bottom, ok := blockHalves(cell.Glyph); ok {
+				mid := r.Min.Y + opts.CellHeight/2
+				if top {
+					draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, mid), image.NewUniform(fg), image.Point{}, draw.Src)
+				}
+				if bottom {
+					draw.Draw(img, image.Rect(r.Min.X, mid, r.Max.X, r.Max.Y), image.NewUniform(fg), image.Point{}, draw.Src)
+				}
+				continue
+			}
+			drawGlyph(img, glyphBitmap(cell.Glyph), r.Min.X+offX, r.Min.Y+offY, scale, fg, cell.Attrs)
+		}
+	}
+}
+
+// drawGlyph paints the set pixels of a glyph with its top-left corner at x, y.
+// Bold glyphs are drawn twice a pixel apart, and italic ones slanted.
+func drawGlyph(img *image.RGBA, glyph *[fontHeight]byte, x, y, scale int, fg color.RGBA, attrs Attr) {
+	thick := 1
+	if attrs&AttrBold != 0 {
+		thick = 2
+	}
+	for row, bits := range glyph {
+		slant := 0
+		if attrs&AttrItalic != 0 {
+			slant = (fontHeight - 1 - row) * scale / 6
+		}
+		for col := 0; col < fontWidth; col++ {
+			if bits&(0x40>>col) == 0 {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale+thick-1; dx++ {
+					img.SetRGBA(x+col*scale+dx+slant, y+row*scale+dy, fg)
+				}
+			}
+		}
+	}
+}
+
+// blend returns the color halfway between a and b, for dim text
+func blend(a, b color.RGBA) color.RGBA {
+	mix := func(p, q uint8) uint8 { return uint8((int(p) + int(q)) / 2) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
+
+// WritePNG encodes the contents of b as a PNG image. The same buffer and
+// options always produce byte-identical output.
+func WritePNG(w io.Writer, b *Buffer, opts ImageOptions) error {
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	return enc.Encode(w, DrawImage(b, opts))
+}
+
+// ParseHexColor parses a CSS style color such as "#1e1e2e" or "fff"
+func ParseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	var r, g, b uint8
+	var err error
+	switch len(hex) {
+	case 6:
+		_, err = fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b)
+	case 3:
+		_, err = fmt.Sscanf(hex, "%1x%1x%1x", &r, &g, &b)
+		r, g, b = r*0x11, g*0x11, b*0x11
+	default:
+		err = fmt.Errorf("wrong length")
+	}
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBA{r, g, b, 0xff}, nil
+}
@@ -0,0 +1,83 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	var b Buffer
+	b.SetCell(0, 0, Cell{Glyph: "#", Width: 1, Color: ColorRed})
+	b.SetCell(1, 0, blankCell)
+
+	bg := color.RGBA{0x1e, 0x1e, 0x2e, 0xff}
+	var out bytes.Buffer
+	if err := WritePNG(&out, &b, ImageOptions{Padding: 3, Background: bg}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds(), image.Rect(0, 0, 2*fontWidth+6, fontHeight+6); got != want {
+		t.Errorf("bounds %v, want %v", got, want)
+	}
+
+	// The glyph is red on the background, and the blank cell has none
+	counts := make(map[color.RGBA]int)
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			if x >= 3+fontWidth && c != bg {
+				t.Fatalf("pixel %d,%d of the blank cell is %v", x, y, c)
+			}
+			counts[c]++
+		}
+	}
+	if counts[ansiBasic[1]] == 0 || len(counts) != 2 {
+		t.Errorf("want red on the background, got colors %v", counts)
+	}
+}
+
+func TestWritePNGDeterministic(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 5
+	tree := NewTree(config)
+	if err := tree.Grow(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	var first, second bytes.Buffer
+	if err := WritePNG(&first, tree.Snapshot(), ImageOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePNG(&second, tree.Snapshot(), ImageOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("the same tree encoded to different PNGs")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"#1e1e2e", color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, false},
+		{"fff", color.RGBA{0xff, 0xff, 0xff, 0xff}, false},
+		{"#abc", color.RGBA{0xaa, 0xbb, 0xcc, 0xff}, false},
+		{"#12345", color.RGBA{}, true},
+		{"#gggggg", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHexColor(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHexColor(%q) = %v, %v, want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package bonsai
+
+import (
+	"image"
+	"strconv"
+	"strings"
+)
+
+// MessagePosition says where the message goes relative to the tree
+type MessagePosition string
+
+const (
+	MessageRight MessagePosition = "right" // Boxed, to the right of the tree (default)
+	MessageLeft  MessagePosition = "left"  // Boxed, to the left of the tree
+	MessageBelow MessagePosition = "below" // Plain text under the canvas
+)
+
+// ParseMessagePosition checks a position given by name
+func ParseMessagePosition(s string) (MessagePosition, bool) {
+	switch pos := MessagePosition(strings.ToLower(s)); pos {
+	case MessageRight, MessageLeft, MessageBelow:
+		return pos, true
+	}
+	return "", false
+}
+
+const (
+	boxMargin   = 2 // Columns between the box and the canvas edge
+	minBoxInner = 8 // Narrowest text area worth drawing a box for
+)
+
+// messageLayout is where the message and tree go on the canvas
+type messageLayout struct {
+	lines   [][]Cell        // Wrapped message lines
+	box     image.Rectangle // Box including the border, empty when below
+	centerX int             // Column the tree and pot are centered on
+}
+
+// layoutMessage wraps the message and works out where the box and the tree
+// go. The box may take up to two fifths of the width, and the tree is
+// centered in whatever is left.
+func (bt *Tree) layoutMessage() messageLayout {
+	width, height := bt.config.Width, bt.config.Height
+	layout := messageLayout{centerX: width / 2}
+	if bt.config.Message == "" {
+		return layout
+	}
+
+	text := styledText(bt.config.Message)
+	pos := bt.config.MessagePos
+	maxInner := width*2/5 - 4 - boxMargin
+	if pos == MessageBelow || maxInner < minBoxInner {
+		layout.lines = wrapLines(text, width)
+		return layout
+	}
+
+	// Shrink the box to the text when it is short
+	inner := 0
+	for _, line := range wrapLines(text, maxInner) {
+		inner = max(inner, lineWidth(line))
+	}
+	layout.lines = wrapLines(text, inner)
+	boxW := inner + 4 // Border and one column of padding on each side
+	boxH := len(layout.lines) + 2
+
+	// Sit the box at about 70% of the height, next to the pot
+	top := min(height*7/10-boxH/2, height-boxH)
+	top = max(top, 0)
+	if pos == MessageLeft {
+		layout.box = image.Rect(boxMargin, top, boxMargin+boxW, top+boxH)
+		layout.centerX = layout.box.Max.X + (width-layout.box.Max.X)/2
+	} else {
+		layout.box = image.Rect(width-boxMargin-boxW, top, width-boxMargin, top+boxH)
+		layout.centerX = layout.box.Min.X / 2
+	}
+	return layout
+}
+
+// drawMessageBox draws the boxed message onto the canvas
+func (bt *Tree) drawMessageBox() {
+	box := bt.layout.box
+	if box.Empty() {
+		return
+	}
+	color := bt.messageColor()
+	style := Cell{Color: color, Owner: OwnerMessage, Z: zMessage}
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < box.Max.X; x++ {
+			top, bottom := y == box.Min.Y, y == box.Max.Y-1
+			left, right := x == box.Min.X, x == box.Max.X-1
+			switch {
+			case (top || bottom) && (left || right):
+				bt.draw(x, y, "+", style)
+			case top || bottom:
+				bt.draw(x, y, "-", style)
+			case left || right:
+				bt.draw(x, y, "|", style)
+			default:
+				bt.draw(x, y, " ", style)
+			}
+		}
+	}
+	for i, line := range bt.layout.lines {
+		x := box.Min.X + 2
+		for _, cell := range line {
+			if cell.Color == "" {
+				cell.Color = color
+			}
+			bt.draw(x, box.Min.Y+1+i, cell.Glyph, cell)
+			x += cell.Width
+		}
+	}
+}
+
+// drawMessageBelow sends an unboxed message to r, below the canvas
+func (bt *Tree) drawMessageBelow(r Renderer) error {
+	if !bt.layout.box.Empty() {
+		return nil
+	}
+	color := bt.messageColor()
+	for i, line := range bt.layout.lines {
+		x := 0
+		for _, cell := range line {
+			if cell.Color == "" {
+				cell.Color = color
+			}
+			cont := cell
+			cont.Glyph, cont.Width = "", 0
+			for j := 0; j < cell.Width; j++ {
+				c := cell
+				if j > 0 {
+					c = cont
+				}
+				if err := r.SetCell(x+j, len(bt.canvas)+1+i, c); err != nil {
+					return err
+				}
+			}
+			x += cell.Width
+		}
+	}
+	return nil
+}
+
+// styledText splits s into lines of glyph cells. ANSI color and attribute
+// escapes in s are turned into cell styles, so they never count towards the
+// width, and other escape sequences are dropped.
+func styledText(s string) [][]Cell {
+	var lines [][]Cell
+	var line []Cell
+	style := Cell{Owner: OwnerMessage, Z: zMessage}
+	var run strings.Builder // Text since the last escape or line break
+
+	// addRun splits the pending text into grapheme clusters
+	addRun := func() {
+		for _, cluster := range graphemes(run.String()) {
+			if width := clusterWidth(cluster); width > 0 {
+				cell := style
+				cell.Glyph, cell.Width = cluster, width
+				line = append(line, cell)
+			}
+		}
+		run.Reset()
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\033' && i+1 < len(runes) && runes[i+1] == '[':
+			// CSI sequence: parameters, then a final byte in @..~
+			addRun()
+			j := i + 2
+			for j < len(runes) && (runes[j] < '@' || runes[j] > '~') {
+				j++
+			}
+			if j < len(runes) && runes[j] == 'm' {
+				applySGR(&style, string(runes[i+2:j]))
+			}
+			i = j
+		case r == '\n':
+			addRun()
+			lines = append(lines, line)
+			line = nil
+		case r == '\t':
+			run.WriteRune(' ')
+		case r < ' ' || r == 0x7f:
+			// Drop other control characters
+		default:
+			run.WriteRune(r)
+		}
+	}
+	addRun()
+	return append(lines, line)
+}
+
+// lineWidth returns the number of columns a line of cells takes up
+func lineWidth(line []Cell) int {
+	width := 0
+	for _, cell := range line {
+		width += cell.Width
+	}
+	return width
+}
+
+// wrapLines word-wraps every line to at most width columns. Words longer
+// than a whole line are broken wherever they hit the edge.
+func wrapLines(lines [][]Cell, width int) [][]Cell {
+	width = max(width, 2) // Room for at least one wide glyph
+	var wrapped [][]Cell
+	for _, line := range lines {
+		var current []Cell
+		for _, word := range splitWords(line) {
+			if len(current) > 0 && lineWidth(current)+1+lineWidth(word) > width {
+				wrapped = append(wrapped, current)
+				current = nil
+			}
+			if len(current) > 0 {
+				current = append(current, blankCell)
+			}
+			for _, cell := range word {
+				if lineWidth(current)+cell.Width > width {
+					wrapped = append(wrapped, current)
+					current = nil
+				}
+				current = append(current, cell)
+			}
+		}
+		wrapped = append(wrapped, current)
+	}
+	return wrapped
+}
+
+// splitWords splits a line of cells on spaces
+func splitWords(line []Cell) [][]Cell {
+	var words [][]Cell
+	start := -1
+	for i, cell := range line {
+		if cell.Glyph == " " {
+			if start >= 0 {
+				words = append(words, line[start:i])
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, line[start:])
+	}
+	return words
+}
+
+// applySGR applies the parameters of a select graphic rendition escape,
+// such as "1;38;5;28", to the colors and attributes of style
+func applySGR(style *Cell, params string) {
+	args := strings.Split(params, ";")
+	for i := 0; i < len(args); i++ {
+		n, err := strconv.Atoi(args[i])
+		if err != nil && args[i] != "" {
+			continue
+		}
+		switch {
+		case n == 0:
+			style.Color, style.Background, style.Attrs = "", "", 0
+		case n == 1:
+			style.Attrs |= AttrBold
+		case n == 2:
+			style.Attrs |= AttrDim
+		case n == 3:
+			style.Attrs |= AttrItalic
+		case n == 22:
+			style.Attrs &^= AttrBold | AttrDim
+		case n == 23:
+			style.Attrs &^= AttrItalic
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			style.Color = "\033[" + args[i] + "m"
+		case n == 39:
+			style.Color = ""
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			style.Background = "\033[" + args[i] + "m"
+		case n == 49:
+			style.Background = ""
+		case n == 38 || n == 48:
+			// Extended color: 5;index or 2;r;g;b
+			end := i + 3
+			if i+1 < len(args) && args[i+1] == "2" {
+				end = i + 5
+			}
+			if end > len(args) {
+				return
+			}
+			code := "\033[" + strings.Join(args[i:end], ";") + "m"
+			if n == 38 {
+				style.Color = code
+			} else {
+				style.Background = code
+			}
+			i = end - 1
+		}
+	}
+}
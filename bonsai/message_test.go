@@ -0,0 +1,182 @@
+package bonsai
+
+import (
+	"context"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lineText returns the glyphs of a line of cells
+func lineText(line []Cell) string {
+	var sb strings.Builder
+	for _, cell := range line {
+		sb.WriteString(cell.Glyph)
+	}
+	return sb.String()
+}
+
+// linesText returns the glyphs of every line of cells
+func linesText(lines [][]Cell) []string {
+	text := make([]string, len(lines))
+	for i, line := range lines {
+		text[i] = lineText(line)
+	}
+	return text
+}
+
+func TestParseMessagePosition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want MessagePosition
+		ok   bool
+	}{
+		{"right", MessageRight, true},
+		{"Left", MessageLeft, true},
+		{"BELOW", MessageBelow, true},
+		{"above", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseMessagePosition(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseMessagePosition(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStyledText(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   []string
+		colors []string // Color of every cell of the first line, if checked
+	}{
+		{"plain", "hi", []string{"hi"}, []string{"", ""}},
+		{"lines", "a\nb\n", []string{"a", "b", ""}, nil},
+		{"color", "a\033[31mb\033[0mc", []string{"abc"}, []string{"", ColorRed, ""}},
+		{"short reset", "\033[32mab\033[mc", []string{"abc"}, []string{ColorGreen, ColorGreen, ""}},
+		{"other escapes dropped", "a\033[2Kb\033[1;1Hc", []string{"abc"}, nil},
+		{"tab", "a\tb", []string{"a b"}, nil},
+		{"control characters dropped", "a\rb\x07c\x7f", []string{"abc"}, nil},
+		{"unterminated escape", "ab\033[3", []string{"ab"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := styledText(tt.in)
+			if got := linesText(lines); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("styledText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if tt.colors == nil {
+				return
+			}
+			for i, cell := range lines[0] {
+				if cell.Color != tt.colors[i] {
+					t.Errorf("cell %d has color %q, want %q", i, cell.Color, tt.colors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWrapLines(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"the quick brown fox", 19, []string{"the quick brown fox"}},
+		{"one  two", 3, []string{"one", "two"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"a bcdefgh", 4, []string{"a", "bcde", "fgh"}},
+		{"first\n\nthird", 10, []string{"first", "", "third"}},
+	}
+	for _, tt := range tests {
+		got := linesText(wrapLines(styledText(tt.in), tt.width))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapLines(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		pos     MessagePosition
+		width   int
+		box     image.Rectangle
+		centerX int
+		lines   int
+	}{
+		{"no message", "", MessageRight, 80, image.Rectangle{}, 40, 0},
+		{"right", "hello", MessageRight, 80, image.Rect(69, 15, 78, 18), 34, 1},
+		{"left", "hello", MessageLeft, 80, image.Rect(2, 15, 11, 18), 45, 1},
+		{"below", "hello", MessageBelow, 80, image.Rectangle{}, 40, 1},
+		{"too narrow for a box", "hello", MessageRight, 30, image.Rectangle{}, 15, 1},
+		{"wrapped", strings.Repeat("word ", 12), MessageRight, 80, image.Rect(50, 14, 78, 19), 25, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Message = tt.message
+			config.MessagePos = tt.pos
+			config.Width = tt.width
+			layout := NewTree(config).layout
+			if layout.box != tt.box || layout.centerX != tt.centerX || len(layout.lines) != tt.lines {
+				t.Errorf("box %v, center %d, %d lines; want box %v, center %d, %d lines",
+					layout.box, layout.centerX, len(layout.lines), tt.box, tt.centerX, tt.lines)
+			}
+			if !layout.box.Empty() && !layout.box.In(image.Rect(0, 0, config.Width, config.Height)) {
+				t.Errorf("box %v is off the canvas", layout.box)
+			}
+		})
+	}
+}
+
+func TestMessageBox(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	config.Message = "hello"
+	tree := NewTree(config)
+	if err := tree.Grow(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	rows := strings.Split(tree.Snapshot().String(), "\n")
+	box := tree.layout.box
+	for i, want := range []string{"+-------+", "| hello |", "+-------+"} {
+		if got := rows[box.Min.Y+i][box.Min.X:box.Max.X]; got != want {
+			t.Errorf("box row %d is %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestApplySGR(t *testing.T) {
+	tests := []struct {
+		params string
+		from   Cell
+		want   Cell
+	}{
+		{"31", Cell{}, Cell{Color: ColorRed}},
+		{"1;32", Cell{}, Cell{Color: ColorGreen, Attrs: AttrBold}},
+		{"2;3", Cell{Attrs: AttrBold}, Cell{Attrs: AttrBold | AttrDim | AttrItalic}},
+		{"22", Cell{Attrs: AttrBold | AttrDim | AttrItalic}, Cell{Attrs: AttrItalic}},
+		{"23", Cell{Attrs: AttrItalic}, Cell{}},
+		{"44", Cell{}, Cell{Background: "\033[44m"}},
+		{"38;5;28", Cell{}, Cell{Color: "\033[38;5;28m"}},
+		{"48;2;1;2;3;1", Cell{}, Cell{Background: "\033[48;2;1;2;3m", Attrs: AttrBold}},
+		{"39;49", Cell{Color: ColorRed, Background: "\033[44m"}, Cell{}},
+		{"", Cell{Color: ColorRed, Attrs: AttrBold}, Cell{}},
+		{"0", Cell{Color: ColorRed, Background: "\033[44m", Attrs: AttrDim}, Cell{}},
+		{"38;5", Cell{Color: ColorRed}, Cell{Color: ColorRed}}, // Cut short
+		{"x;1", Cell{}, Cell{Attrs: AttrBold}},
+	}
+	for _, tt := range tests {
+		got := tt.from
+		applySGR(&got, tt.params)
+		if got != tt.want {
+			t.Errorf("applySGR(%+v, %q) = %+v, want %+v", tt.from, tt.params, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package bonsai
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Palette is a color theme: a list of weighted colors for every part of
+// the tree. A color is "#rrggbb", or a number from 0 to 255 for a color of
+// the xterm palette, and may end in :weight like leaves do. Parts left
+// empty take the classic colors. Light holds the parts that change on a
+// light background.
+type Palette struct {
+	Name     string   `json:"name"`
+	Trunk    []string `json:"trunk,omitempty"`
+	Shoot    []string `json:"shoot,omitempty"`
+	Leaf     []string `json:"leaf,omitempty"`     // Leaves on dying branches
+	DeadLeaf []string `json:"deadLeaf,omitempty"` // Leaves on dead branches, Leaf when empty
+	Pot      []string `json:"pot,omitempty"`
+	Grass    []string `json:"grass,omitempty"`
+	Soil     []string `json:"soil,omitempty"`
+	Message  []string `json:"message,omitempty"` // Message text and box, which have no color in classic
+	Light    *Palette `json:"light,omitempty"`
+}
+
+// The built-in palettes are JSON files, in the same format --theme reads
+//
+//go:embed palettes/*.json
+var paletteFiles embed.FS
+
+// paletteNames lists the built-in palettes, classic first
+var paletteNames = []string{"classic", "autumn", "sakura", "winter", "monochrome"}
+
+// classicPalette fills in the parts other palettes leave out
+var classicPalette, _ = LookupPalette("classic")
+
+// Palettes returns the names of the built-in palettes
+func Palettes() []string {
+	return append([]string(nil), paletteNames...)
+}
+
+// LookupPalette returns the built-in palette with the given name
+func LookupPalette(name string) (Palette, bool) {
+	name = strings.ToLower(name)
+	for _, builtin := range paletteNames {
+		if name != builtin {
+			continue
+		}
+		f, err := paletteFiles.Open("palettes/" + name + ".json")
+		if err != nil {
+			return Palette{}, false
+		}
+		defer f.Close()
+		p, err := LoadPalette(f)
+		return p, err == nil
+	}
+	return Palette{}, false
+}
+
+// LoadPalette reads a palette written as JSON, in the same layout as Palette
+func LoadPalette(r io.Reader) (Palette, error) {
+	var p Palette
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return Palette{}, fmt.Errorf("reading theme: %w", err)
+	}
+	if err := p.validate(); err != nil {
+		return Palette{}, fmt.Errorf("invalid theme: %w", err)
+	}
+	return p, nil
+}
+
+// parts returns every part of the palette by name
+func (p *Palette) parts() []struct {
+	name   string
+	colors *[]string
+} {
+	return []struct {
+		name   string
+		colors *[]string
+	}{
+		{"trunk", &p.Trunk}, {"shoot", &p.Shoot}, {"leaf", &p.Leaf}, {"deadLeaf", &p.DeadLeaf},
+		{"pot", &p.Pot}, {"grass", &p.Grass}, {"soil", &p.Soil}, {"message", &p.Message},
+	}
+}
+
+// validate checks every color of the palette
+func (p Palette) validate() error {
+	for _, part := range p.parts() {
+		if _, err := parseColorSet(*part.colors); err != nil {
+			return fmt.Errorf("%s: %w", part.name, err)
+		}
+	}
+	if p.Light != nil {
+		if p.Light.Light != nil {
+			return errors.New("light: can't have a light variant of its own")
+		}
+		if err := p.Light.validate(); err != nil {
+			return fmt.Errorf("light %w", err)
+		}
+	}
+	return nil
+}
+
+// For returns the palette to use on background bg: on a light one, the
+// parts of Light replace those it has
+func (p Palette) For(bg Background) Palette {
+	light := p.Light
+	p.Light = nil
+	if bg != BackgroundLight || light == nil {
+		return p
+	}
+	lightParts := light.parts()
+	for i, part := range p.parts() {
+		if colors := *lightParts[i].colors; len(colors) > 0 {
+			*part.colors = colors
+		}
+	}
+	return p
+}
+
+// parseColorSet parses weighted colors into escape codes
+func parseColorSet(entries []string) (weightedSet, error) {
+	return parseWeighted("color", entries, colorCode)
+}
+
+// colorCode turns a palette color into its escape code
+func colorCode(s string) (string, error) {
+	if strings.HasPrefix(s, "#") {
+		c, err := ParseHexColor(s)
+		if err != nil {
+			return "", fmt.Errorf("is not #rrggbb: %q", s)
+		}
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B), nil
+	}
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil || n < 0 || n > 255:
+		return "", fmt.Errorf("is not #rrggbb or 0-255: %q", s)
+	case n < 8:
+		return fmt.Sprintf("\033[%dm", 30+n), nil
+	case n < 16:
+		return fmt.Sprintf("\033[%dm", 90+n-8), nil
+	}
+	return fmt.Sprintf("\033[38;5;%dm", n), nil
+}
+
+// paletteTable is a Palette parsed, with the classic colors filled in
+type paletteTable struct {
+	trunk, shoot, leaf, deadLeaf weightedSet
+	pot, grass, soil, message    weightedSet
+
+	// Gradients for the gradient shading
+	bark, foliage, deadFoliage gradient
+}
+
+// newPaletteTable builds the color sets of config's palette. Colors that
+// don't parse fall back to classic; Config.Validate reports them.
+func newPaletteTable(config *Config) *paletteTable {
+	p := config.Palette.For(config.Background)
+	if len(p.DeadLeaf) == 0 && len(p.Leaf) > 0 {
+		p.DeadLeaf = p.Leaf
+	}
+	classic := classicPalette.For(config.Background)
+	if len(classic.DeadLeaf) == 0 {
+		classic.DeadLeaf = classic.Leaf
+	}
+
+	sets := make(map[string]weightedSet)
+	classicParts := classic.parts()
+	for i, part := range p.parts() {
+		set, err := parseColorSet(*part.colors)
+		if err != nil || len(set.items) == 0 {
+			set, _ = parseColorSet(*classicParts[i].colors)
+		}
+		sets[part.name] = set
+	}
+	return &paletteTable{
+		trunk:       sets["trunk"],
+		shoot:       sets["shoot"],
+		leaf:        sets["leaf"],
+		deadLeaf:    sets["deadLeaf"],
+		pot:         sets["pot"],
+		grass:       sets["grass"],
+		soil:        sets["soil"],
+		message:     sets["message"],
+		bark:        newGradient(sets["trunk"], sets["shoot"]),
+		foliage:     newGradient(sets["leaf"]),
+		deadFoliage: newGradient(sets["deadLeaf"]),
+	}
+}
+
+// messageColor picks the color for message text and box that has none
+func (bt *Tree) messageColor() string {
+	if !bt.config.UseColors {
+		return ""
+	}
+	return bt.palette.message.pick(bt.looks)
+}
@@ -0,0 +1,164 @@
+package bonsai
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLookupPalette(t *testing.T) {
+	names := Palettes()
+	if len(names) == 0 || names[0] != "classic" {
+		t.Fatalf("Palettes() = %q, want classic first", names)
+	}
+	for _, name := range names {
+		p, ok := LookupPalette(strings.ToUpper(name))
+		if !ok || p.Name != name {
+			t.Errorf("LookupPalette(%q) = %q, %v", strings.ToUpper(name), p.Name, ok)
+		}
+	}
+	if _, ok := LookupPalette("neon"); ok {
+		t.Error("unknown palette was found")
+	}
+}
+
+func TestColorCode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"#ffb7c5", "\033[38;2;255;183;197m", false},
+		{"#fff", "\033[38;2;255;255;255m", false},
+		{"2", "\033[32m", false},
+		{"10", "\033[92m", false},
+		{"28", "\033[38;5;28m", false},
+		{"256", "", true},
+		{"-1", "", true},
+		{"#ggg", "", true},
+		{"green", "", true},
+	}
+	for _, tt := range tests {
+		got, err := colorCode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("colorCode(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadPalette(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"colors", `{"name": "mine", "trunk": ["#4a2f27"], "leaf": ["10:3", "#fff"]}`, false},
+		{"nothing set", `{}`, false},
+		{"bad hex", `{"leaf": ["#12345"]}`, true},
+		{"out of range", `{"pot": ["300"]}`, true},
+		{"negative weight", `{"soil": ["3:-1"]}`, true},
+		{"all weights 0", `{"grass": ["10:0"]}`, true},
+		{"light variant", `{"leaf": ["10"], "light": {"leaf": ["28"]}}`, false},
+		{"bad light color", `{"light": {"leaf": ["#xyz"]}}`, true},
+		{"light of light", `{"light": {"light": {}}}`, true},
+		{"not JSON", `palette`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPalette(strings.NewReader(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadPalette() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// paletteCodes returns the escape codes of every color in p
+func paletteCodes(t *testing.T, p Palette) map[string]bool {
+	codes := make(map[string]bool)
+	for _, part := range p.parts() {
+		set, err := parseColorSet(*part.colors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, code := range set.items {
+			codes[code] = true
+		}
+	}
+	return codes
+}
+
+func TestPaletteFor(t *testing.T) {
+	p, _ := LookupPalette("classic")
+	dark, light := p.For(BackgroundDark), p.For(BackgroundLight)
+	if dark.Light != nil || light.Light != nil {
+		t.Error("a palette picked for a background still has a light variant")
+	}
+	if !slices.Equal(dark.Trunk, p.Trunk) || !slices.Equal(light.Trunk, p.Light.Trunk) {
+		t.Errorf("trunk is %q on dark and %q on light", dark.Trunk, light.Trunk)
+	}
+
+	// Parts the light variant leaves out stay as they are
+	p = Palette{Leaf: []string{"10"}, Soil: []string{"3"}, Light: &Palette{Leaf: []string{"28"}}}
+	light = p.For(BackgroundLight)
+	if !slices.Equal(light.Leaf, []string{"28"}) || !slices.Equal(light.Soil, []string{"3"}) {
+		t.Errorf("light variant gave leaf %q and soil %q", light.Leaf, light.Soil)
+	}
+}
+
+func TestPaletteColors(t *testing.T) {
+	for _, name := range Palettes() {
+		for _, bg := range []Background{BackgroundDark, BackgroundLight} {
+			t.Run(name+"/"+string(bg), func(t *testing.T) {
+				config := DefaultConfig()
+				config.Seed = 4
+				config.Palette, _ = LookupPalette(name)
+				config.Background = bg
+				tree := NewTree(config)
+				if err := tree.Grow(context.Background(), nil); err != nil {
+					t.Fatal(err)
+				}
+				codes := paletteCodes(t, config.Palette.For(bg))
+				if name != "classic" {
+					// Parts the palette leaves out take the classic colors
+					for code := range paletteCodes(t, classicPalette.For(bg)) {
+						codes[code] = true
+					}
+				}
+				snap := tree.Snapshot()
+				width, height := snap.Size()
+				for y := 0; y < height; y++ {
+					for x := 0; x < width; x++ {
+						if cell := snap.Cell(x, y); !cell.Blank() && !codes[cell.Color] {
+							t.Fatalf("cell %d,%d has color %q, which isn't in the palette", x, y, cell.Color)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPaletteWithoutColors(t *testing.T) {
+	for _, name := range Palettes() {
+		config := DefaultConfig()
+		config.Seed = 4
+		config.Message = "hi"
+		config.UseColors = false
+		config.Palette, _ = LookupPalette(name)
+		tree := NewTree(config)
+		if err := tree.Grow(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+		snap := tree.Snapshot()
+		width, height := snap.Size()
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if cell := snap.Cell(x, y); cell.Color != "" || cell.Background != "" {
+					t.Fatalf("%s: cell %d,%d is colored with colors off", name, x, y)
+				}
+			}
+		}
+	}
+}
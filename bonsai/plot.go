@@ -0,0 +1,234 @@
+package bonsai
+
+import "strings"
+
+// The plotted styles grow the tree on a grid of points and draw every step
+// as dots finer than a cell: Braille patterns pack 2x4 dots into a cell,
+// and half blocks two square pixels. A grid point is one dot wide and two
+// tall, so the tree keeps its proportions, and every step is joined to the
+// one before it with a straight line of dots. The pot and message are
+// drawn in cells, as text or as filled blocks.
+
+// DrawStyle says how the tree is put on the canvas
+type DrawStyle string
+
+const (
+	StyleText    DrawStyle = "text"    // A glyph per step (default)
+	StyleBraille DrawStyle = "braille" // Braille dots, on a grid twice as fine
+	StylePixels  DrawStyle = "pixels"  // Half blocks, two colored pixels per cell
+)
+
+// ParseDrawStyle checks a style given by name
+func ParseDrawStyle(s string) (DrawStyle, bool) {
+	switch style := DrawStyle(strings.ToLower(s)); style {
+	case StyleText, StyleBraille, StylePixels:
+		return style, true
+	}
+	return "", false
+}
+
+const (
+	brailleBlank = 0x2800 // Braille pattern with no dots raised
+	upperHalf    = "▀"
+	lowerHalf    = "▄"
+	fullBlock    = "█"
+)
+
+// brailleBits is the bit of each dot in a Braille pattern, by row and column
+var brailleBits = [4][2]uint8{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// dot is one raised Braille dot or painted pixel
+type dot struct {
+	on    bool
+	color string
+	owner Owner
+	z     int
+}
+
+// plotted reports whether the tree is drawn in dots rather than glyphs
+func (bt *Tree) plotted() bool {
+	return bt.config.Style == StyleBraille || bt.config.Style == StylePixels
+}
+
+// scale returns how many grid points there are per cell along each axis
+func (bt *Tree) scale() int {
+	if bt.config.Style == StyleBraille {
+		return 2
+	}
+	return 1
+}
+
+// dotsPerCell returns how many dots a cell holds across and down
+func (bt *Tree) dotsPerCell() (int, int) {
+	if bt.config.Style == StyleBraille {
+		return 2, 4
+	}
+	return 1, 2
+}
+
+// plotStep draws a branch step from grid point (fromX, fromY) to (x, y) in
+// the colors, owner and Z of style. Leaves are drawn as a single dot,
+// alternating between the two of the point so foliage keeps some texture,
+// branches as a line, and the trunk as a line two dots thick.
+func (bt *Tree) plotStep(fromX, fromY, x, y int, branchType BranchType, leaf bool, style Cell) {
+	if leaf {
+		bt.setDot(x, 2*y+abs(x+y)%2, style)
+		return
+	}
+	bt.plotLine(fromX, 2*fromY, x, 2*y, style)
+	bt.plotPoint(x, y, style)
+	if branchType == Trunk {
+		bt.plotLine(fromX+1, 2*fromY, x+1, 2*y, style)
+		bt.plotPoint(x+1, y, style)
+	}
+}
+
+// plotPoint raises both dots of a grid point
+func (bt *Tree) plotPoint(x, y int, style Cell) {
+	bt.setDot(x, 2*y, style)
+	bt.setDot(x, 2*y+1, style)
+}
+
+// plotLine raises the dots on the line between two dots, inclusive
+func (bt *Tree) plotLine(x0, y0, x1, y1 int, style Cell) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		bt.setDot(x0, y0, style)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// setDot raises a single dot and redraws its cell. Cells holding text,
+// such as the grass, the rows of the pot and dots with a higher Z are left
+// as they are.
+func (bt *Tree) setDot(x, y int, style Cell) {
+	if x < 0 || y < 0 || y >= len(bt.dots) || x >= len(bt.dots[y]) {
+		return
+	}
+	dw, dh := bt.dotsPerCell()
+	cx, cy := x/dw, y/dh
+	if !bt.hasDots(cx, cy) && !bt.canvas[cy][cx].Blank() {
+		return
+	}
+	if d := bt.dots[y][x]; d.on && d.z > style.Z {
+		return
+	}
+	bt.dots[y][x] = dot{on: true, color: style.Color, owner: style.Owner, z: style.Z}
+	bt.putCell(cx, cy, bt.dotCell(cx, cy, style))
+}
+
+// hasDots reports whether any dot of a cell is raised
+func (bt *Tree) hasDots(cx, cy int) bool {
+	dw, dh := bt.dotsPerCell()
+	for y := cy * dh; y < (cy+1)*dh; y++ {
+		for x := cx * dw; x < (cx+1)*dw; x++ {
+			if bt.dots[y][x].on {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dotCell returns the cell that shows the dots of cell cx, cy, after a dot
+// in style was raised. The cell belongs to its topmost dot, the one raised
+// last among those with the highest Z. A Braille cell has the single color
+// of that dot; half blocks show the colors of both pixels.
+func (bt *Tree) dotCell(cx, cy int, style Cell) Cell {
+	dw, dh := bt.dotsPerCell()
+	top := dot{color: style.Color, owner: style.Owner, z: style.Z}
+	for y := cy * dh; y < (cy+1)*dh; y++ {
+		for x := cx * dw; x < (cx+1)*dw; x++ {
+			if d := bt.dots[y][x]; d.on && d.z > top.z {
+				top = d
+			}
+		}
+	}
+	cell := Cell{Width: 1, Color: top.color, Owner: top.owner, Z: top.z}
+
+	if bt.config.Style == StyleBraille {
+		pattern := 0
+		for row, bits := range brailleBits {
+			for col, bit := range bits {
+				if bt.dots[cy*4+row][cx*2+col].on {
+					pattern |= int(bit)
+				}
+			}
+		}
+		cell.Glyph = string(rune(brailleBlank + pattern))
+		return cell
+	}
+
+	upper, lower := bt.dots[cy*2][cx], bt.dots[cy*2+1][cx]
+	switch {
+	case upper.on && lower.on && upper.color == lower.color:
+		cell.Glyph, cell.Color = fullBlock, upper.color
+	case upper.on && lower.on:
+		cell.Glyph, cell.Color, cell.Background = upperHalf, upper.color, ansiBackground(lower.color)
+	case upper.on:
+		cell.Glyph, cell.Color = upperHalf, upper.color
+	default:
+		cell.Glyph, cell.Color = lowerHalf, lower.color
+	}
+	return cell
+}
+
+// blockHalves reports which halves of a cell a block glyph fills, so the
+// exporters can draw half blocks as exact pixels rather than as text
+func blockHalves(glyph string) (top, bottom, ok bool) {
+	switch glyph {
+	case upperHalf:
+		return true, false, true
+	case lowerHalf:
+		return false, true, true
+	case fullBlock:
+		return true, true, true
+	}
+	return false, false, false
+}
+
+// resetDots clears the dots, covering the canvas above the pot
+func (bt *Tree) resetDots() {
+	bt.dots = nil
+	if !bt.plotted() {
+		return
+	}
+	rows := len(bt.canvas)
+	if pot, ok := bt.pot(); ok {
+		rows = max(rows-len(pot.Lines)+1, 0) // The grass row can hold dots around the text
+	}
+	dw, dh := bt.dotsPerCell()
+	bt.dots = make([][]dot, rows*dh)
+	for i := range bt.dots {
+		bt.dots[i] = make([]dot, bt.config.Width*dw)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
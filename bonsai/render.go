@@ -0,0 +1,251 @@
+package bonsai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Cell is a single column of the canvas. A glyph two columns wide is stored
+// in its left cell, and the cell to its right is a continuation cell with
+// no glyph and a width of 0.
+type Cell struct {
+	Glyph      string // One grapheme cluster
+	Width      int    // Columns the glyph takes up, 0 for a continuation cell
+	Color      string // ANSI escape for the foreground, empty for none
+	Background string // ANSI escape for the background, empty for none
+	Attrs      Attr   // Text attributes
+	Owner      Owner  // Part of the tree the cell was drawn for
+	Z          int    // Stacking order, a cell is never drawn over one with a higher Z
+}
+
+// Attr is a set of text attributes
+type Attr uint8
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrItalic
+)
+
+// escape returns the escape code that turns the attributes on
+func (a Attr) escape() string {
+	var params []string
+	for i, param := range []string{"1", "2", "3"} {
+		if a&(1<<i) != 0 {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+// Owner is the part of the tree a cell belongs to
+type Owner uint8
+
+const (
+	OwnerNone Owner = iota
+	OwnerTrunk
+	OwnerShoot
+	OwnerLeaf
+	OwnerPot
+	OwnerMessage
+)
+
+// blankCell is what an untouched canvas position holds
+var blankCell = Cell{Glyph: " ", Width: 1}
+
+// Blank reports whether the cell shows nothing
+func (c Cell) Blank() bool {
+	return c.Width == 0 || c.Glyph == " " || c.Glyph == ""
+}
+
+// writeStyled writes the glyph of a cell wrapped in its attribute and
+// color escapes
+func writeStyled(w io.Writer, cell Cell) {
+	if cell.Color == "" && cell.Background == "" && cell.Attrs == 0 {
+		io.WriteString(w, cell.Glyph)
+		return
+	}
+	fmt.Fprintf(w, "%s%s%s%s%s", cell.Attrs.escape(), cell.Color, cell.Background, cell.Glyph, ColorReset)
+}
+
+// Renderer receives the draw operations of a tree. Cells are drawn between
+// BeginFrame and EndFrame; a frame is one growth step in live mode, or the
+// whole canvas when a finished tree is rendered.
+type Renderer interface {
+	BeginFrame() error
+	SetCell(x, y int, cell Cell) error
+	EndFrame() error
+}
+
+// flush pushes buffered output through, if the writer supports it
+func flush(w io.Writer) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// TerminalRenderer draws cells in place using ANSI cursor movement, which
+// is what live and interactive modes use.
+type TerminalRenderer struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewTerminalRenderer creates a TerminalRenderer writing to w
+func NewTerminalRenderer(w io.Writer) *TerminalRenderer {
+	return &TerminalRenderer{w: w}
+}
+
+// BeginFrame starts collecting a frame
+func (r *TerminalRenderer) BeginFrame() error {
+	r.buf.Reset()
+	return nil
+}
+
+// SetCell moves the cursor to the cell and draws it. Continuation cells
+// are covered by the wide glyph to their left, so they draw nothing.
+func (r *TerminalRenderer) SetCell(x, y int, cell Cell) error {
+	if cell.Width == 0 {
+		return nil
+	}
+	fmt.Fprintf(&r.buf, "\033[%d;%dH", y+1, x+1) // Convert to 1-based coordinates
+	writeStyled(&r.buf, cell)
+	return nil
+}
+
+// EndFrame writes the frame in one go
+func (r *TerminalRenderer) EndFrame() error {
+	if _, err := r.w.Write(r.buf.Bytes()); err != nil {
+		return err
+	}
+	r.buf.Reset()
+	return flush(r.w) // Ensure immediate output
+}
+
+// TextRenderer keeps its own copy of the canvas and writes it out as plain
+// lines at the end of every frame. With colors enabled each colored cell is
+// wrapped in its escape code, otherwise the output is pure text.
+type TextRenderer struct {
+	w      io.Writer
+	colors bool
+	buf    Buffer
+}
+
+// NewTextRenderer creates a TextRenderer writing to w
+func NewTextRenderer(w io.Writer, colors bool) *TextRenderer {
+	return &TextRenderer{w: w, colors: colors}
+}
+
+// BeginFrame starts a frame
+func (r *TextRenderer) BeginFrame() error {
+	return nil
+}
+
+// SetCell records the cell
+func (r *TextRenderer) SetCell(x, y int, cell Cell) error {
+	return r.buf.SetCell(x, y, cell)
+}
+
+// EndFrame writes the whole canvas
+func (r *TextRenderer) EndFrame() error {
+	var sb strings.Builder
+	for _, row := range r.buf.cells {
+		for _, cell := range row {
+			if cell.Width == 0 {
+				continue
+			}
+			if r.colors {
+				writeStyled(&sb, cell)
+			} else {
+				sb.WriteString(cell.Glyph)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	if _, err := io.WriteString(r.w, sb.String()); err != nil {
+		return err
+	}
+	return flush(r.w)
+}
+
+// Buffer is an in-memory renderer, useful for tests and for embedding a
+// tree in another program. The zero value is ready to use and grows to fit
+// whatever is drawn into it.
+type Buffer struct {
+	cells  [][]Cell
+	width  int
+	frames int
+}
+
+// BeginFrame starts a frame
+func (b *Buffer) BeginFrame() error {
+	return nil
+}
+
+// SetCell stores the cell, growing the buffer if needed
+func (b *Buffer) SetCell(x, y int, cell Cell) error {
+	if x < 0 || y < 0 {
+		return nil
+	}
+	if x >= b.width {
+		b.width = x + 1
+		for i := range b.cells {
+			b.cells[i] = growRow(b.cells[i], b.width)
+		}
+	}
+	for len(b.cells) <= y {
+		b.cells = append(b.cells, growRow(nil, b.width))
+	}
+	b.cells[y][x] = cell
+	return nil
+}
+
+// EndFrame counts the frame
+func (b *Buffer) EndFrame() error {
+	b.frames++
+	return nil
+}
+
+// Size returns the width and height of everything drawn so far
+func (b *Buffer) Size() (int, int) {
+	return b.width, len(b.cells)
+}
+
+// Cell returns the cell at x, y, or a blank cell outside the buffer
+func (b *Buffer) Cell(x, y int) Cell {
+	if y < 0 || y >= len(b.cells) || x < 0 || x >= b.width {
+		return blankCell
+	}
+	return b.cells[y][x]
+}
+
+// Frames returns the number of frames drawn so far
+func (b *Buffer) Frames() int {
+	return b.frames
+}
+
+// String returns the buffer as plain text, one line per row
+func (b *Buffer) String() string {
+	var sb strings.Builder
+	for _, row := range b.cells {
+		for _, cell := range row {
+			sb.WriteString(cell.Glyph)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// growRow pads row with blank cells up to width
+func growRow(row []Cell, width int) []Cell {
+	for len(row) < width {
+		row = append(row, blankCell)
+	}
+	return row
+}
@@ -0,0 +1,113 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	var b Buffer
+	if w, h := b.Size(); w != 0 || h != 0 {
+		t.Fatalf("empty buffer is %dx%d", w, h)
+	}
+	b.BeginFrame()
+	b.SetCell(2, 1, Cell{Glyph: "a", Width: 1})
+	b.SetCell(-1, 0, Cell{Glyph: "x", Width: 1}) // Off the buffer, ignored
+	b.SetCell(0, 0, Cell{Glyph: "木", Width: 2})
+	b.SetCell(1, 0, Cell{Width: 0})
+	b.EndFrame()
+
+	if w, h := b.Size(); w != 3 || h != 2 {
+		t.Errorf("Size() = %dx%d, want 3x2", w, h)
+	}
+	if got := b.Cell(2, 1).Glyph; got != "a" {
+		t.Errorf("Cell(2, 1) = %q, want \"a\"", got)
+	}
+	if got := b.Cell(5, 5); got != blankCell {
+		t.Errorf("Cell outside the buffer = %+v, want blank", got)
+	}
+	if got, want := b.String(), "木 \n  a\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if b.Frames() != 1 {
+		t.Errorf("Frames() = %d, want 1", b.Frames())
+	}
+}
+
+func TestTextRenderer(t *testing.T) {
+	cells := []struct {
+		x, y int
+		cell Cell
+	}{
+		{0, 0, Cell{Glyph: "a", Width: 1, Color: ColorGreen}},
+		{1, 0, Cell{Glyph: "b", Width: 1, Attrs: AttrBold}},
+		{0, 1, Cell{Glyph: "木", Width: 2}},
+		{1, 1, Cell{Width: 0}},
+	}
+	tests := []struct {
+		colors bool
+		want   string
+	}{
+		{false, "ab\n木\n"},
+		{true, ColorGreen + "a" + ColorReset + "\033[1mb" + ColorReset + "\n木\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		r := NewTextRenderer(&out, tt.colors)
+		r.BeginFrame()
+		for _, c := range cells {
+			r.SetCell(c.x, c.y, c.cell)
+		}
+		if err := r.EndFrame(); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("colors %v: wrote %q, want %q", tt.colors, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalRenderer(t *testing.T) {
+	var out bytes.Buffer
+	r := NewTerminalRenderer(&out)
+	r.BeginFrame()
+	r.SetCell(2, 3, Cell{Glyph: "&", Width: 1})
+	r.SetCell(4, 0, Cell{Glyph: "木", Width: 2})
+	r.SetCell(5, 0, Cell{Width: 0})
+	if out.Len() != 0 {
+		t.Errorf("wrote %q before the frame ended", out.String())
+	}
+	if err := r.EndFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "\033[4;3H&\033[1;5H木"; got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
+
+func TestGrowWith(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	tree := NewTree(config)
+	var grown Buffer
+	if err := tree.GrowWith(context.Background(), &grown); err != nil {
+		t.Fatal(err)
+	}
+	if grown.Frames() < 2 {
+		t.Errorf("growth drew %d frames", grown.Frames())
+	}
+	var rendered Buffer
+	if err := tree.RenderWith(context.Background(), &rendered); err != nil {
+		t.Fatal(err)
+	}
+	// Growth only reaches the cells it draws, rendering covers the canvas
+	width, height := rendered.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if got, want := grown.Cell(x, y), rendered.Cell(x, y); got != want {
+				t.Fatalf("cell %d,%d grew as %+v but renders as %+v", x, y, got, want)
+			}
+		}
+	}
+}
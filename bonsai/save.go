@@ -0,0 +1,46 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// saveVersion is bumped whenever the save file layout changes
+const saveVersion = 1
+
+// saveFile is the layout of a saved tree
+type saveFile struct {
+	Version int     `json:"version"`
+	Config  *Config `json:"config"`
+}
+
+// SaveConfig writes config to w, so that LoadConfig can grow the exact same
+// tree again later. The algorithm is always written out, so the tree stays
+// the same when a newer one comes along.
+func SaveConfig(w io.Writer, config *Config) error {
+	saved := *config
+	saved.Algorithm = config.algorithm()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(saveFile{Version: saveVersion, Config: &saved})
+}
+
+// LoadConfig reads a config written by SaveConfig. Fields missing from the
+// file keep their DefaultConfig values.
+func LoadConfig(r io.Reader) (*Config, error) {
+	save := saveFile{Config: DefaultConfig()}
+	if err := json.NewDecoder(r).Decode(&save); err != nil {
+		return nil, fmt.Errorf("reading saved tree: %w", err)
+	}
+	if save.Version < 1 || save.Version > saveVersion {
+		return nil, fmt.Errorf("unsupported save file version %d", save.Version)
+	}
+	if save.Config.Algorithm == "" {
+		save.Config.Algorithm = AlgorithmV1 // Saved before there were others
+	}
+	if err := save.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid saved tree: %w", err)
+	}
+	return save.Config, nil
+}
@@ -0,0 +1,114 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 42
+	config.Message = "hi"
+	config.Leaves = []string{"&", "#"}
+
+	var out bytes.Buffer
+	if err := SaveConfig(&out, config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Algorithm != "" {
+		t.Error("saving changed the config")
+	}
+	loaded, err := LoadConfig(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := *config
+	want.Algorithm = LatestAlgorithm // Saved trees always record it
+	if !reflect.DeepEqual(loaded, &want) {
+		t.Fatalf("loaded %+v\nwant %+v", loaded, &want)
+	}
+
+	// The loaded config grows the same tree
+	saved, again := NewTree(config), NewTree(loaded)
+	if err := saved.Grow(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := again.Grow(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Snapshot().String() != again.Snapshot().String() {
+		t.Error("the loaded config grew a different tree")
+	}
+}
+
+func TestSaveConfigDisplay(t *testing.T) {
+	// How a tree is shown belongs to the run, not the tree
+	config := DefaultConfig()
+	config.Seed = 42
+	config.Live = true
+	config.TimeStep = 0.5
+	config.FPS = 30
+	config.Duration = 5
+
+	var out bytes.Buffer
+	if err := SaveConfig(&out, config); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"live"`, `"time"`, `"fps"`, `"duration"`} {
+		if strings.Contains(out.String(), key) {
+			t.Errorf("saved tree has %s:\n%s", key, out.String())
+		}
+	}
+	loaded, err := LoadConfig(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultConfig()
+	if loaded.Live != defaults.Live || loaded.TimeStep != defaults.TimeStep ||
+		loaded.FPS != defaults.FPS || loaded.Duration != defaults.Duration {
+		t.Errorf("loaded display settings live %v, time %v, fps %v, duration %v",
+			loaded.Live, loaded.TimeStep, loaded.FPS, loaded.Duration)
+	}
+
+	// Files saved before they were left out don't bring them back either
+	old := `{"version": 1, "config": {"seed": 42, "live": true, "time": 0.5, "fps": 30, "duration": 5}}`
+	loaded, err = LoadConfig(strings.NewReader(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Live || loaded.TimeStep != defaults.TimeStep || loaded.FPS != 0 || loaded.Duration != 0 {
+		t.Errorf("old save file set live %v, time %v, fps %v, duration %v",
+			loaded.Live, loaded.TimeStep, loaded.FPS, loaded.Duration)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+		check   func(*Config) bool
+	}{
+		{"missing fields keep defaults", `{"version": 1, "config": {"seed": 7}}`, false,
+			func(c *Config) bool { return c.Seed == 7 && c.LifeStart == 32 && c.Width == 80 }},
+		{"no algorithm is v1", `{"version": 1, "config": {}}`, false,
+			func(c *Config) bool { return c.Algorithm == AlgorithmV1 }},
+		{"newer version", `{"version": 99, "config": {}}`, true, nil},
+		{"invalid config", `{"version": 1, "config": {"life": 300}}`, true, nil},
+		{"not JSON", `bonsai`, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(strings.NewReader(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.check != nil && !tt.check(config) {
+				t.Errorf("loaded %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// branchTypeNames are the names branch types are known by outside Go
+var branchTypeNames = [...]string{
+	Trunk:      "trunk",
+	ShootLeft:  "shootLeft",
+	ShootRight: "shootRight",
+	Dying:      "dying",
+	Dead:       "dead",
+}
+
+// String returns the name of the branch type
+func (t BranchType) String() string {
+	if t < 0 || int(t) >= len(branchTypeNames) {
+		return fmt.Sprintf("BranchType(%d)", int(t))
+	}
+	return branchTypeNames[t]
+}
+
+// MarshalText encodes the branch type as its name
+func (t BranchType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(branchTypeNames) {
+		return nil, fmt.Errorf("unknown branch type %d", int(t))
+	}
+	return []byte(branchTypeNames[t]), nil
+}
+
+// UnmarshalText decodes a branch type from its name
+func (t *BranchType) UnmarshalText(text []byte) error {
+	for i, name := range branchTypeNames {
+		if name == string(text) {
+			*t = BranchType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown branch type %q", text)
+}
+
+// Scene describes the geometry of a grown tree rather than its characters.
+// Coordinates are on the grid the tree grew on, which is the canvas, or
+// twice its size in the Braille style.
+type Scene struct {
+	Width    int           `json:"width"`
+	Height   int           `json:"height"`
+	Seed     int64         `json:"seed"`
+	Pot      int           `json:"pot"` // Config.BaseType, 0 is none
+	Branches []SceneBranch `json:"branches"`
+	Leaves   []SceneLeaf   `json:"leaves"`
+}
+
+// SceneBranch is one call of Branch: where it started and every point it
+// moved through, in order. Points may lie outside the canvas.
+type SceneBranch struct {
+	ID     int        `json:"id"`
+	Type   BranchType `json:"type"`
+	Parent int        `json:"parent"` // ID of the branch it grew from, -1 for the trunk
+	Start  Point      `json:"start"`
+	Life   int        `json:"life"`
+	Points []Point    `json:"points"`
+}
+
+// SceneLeaf is a canvas cell drawn with a leaf glyph
+type SceneLeaf struct {
+	Point
+	Branch int    `json:"branch"`
+	Glyph  string `json:"glyph"`
+	Color  string `json:"color,omitempty"` // Hex color, if colors are enabled
+}
+
+// Scene returns the geometry recorded by the last Grow. It has no branches
+// or leaves unless Config.RecordScene was set.
+func (bt *Tree) Scene() *Scene {
+	return &bt.scene
+}
+
+// WriteJSON writes the scene of the grown tree as indented JSON. The tree
+// must have grown with Config.RecordScene set.
+func (bt *Tree) WriteJSON(w io.Writer) error {
+	if !bt.config.RecordScene {
+		return fmt.Errorf("no scene was recorded, set Config.RecordScene")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bt.scene)
+}
+
+// resetScene starts recording a new scene
+func (bt *Tree) resetScene() {
+	bt.scene = Scene{
+		Width:    bt.config.Width * bt.scale(),
+		Height:   bt.config.Height * bt.scale(),
+		Seed:     bt.config.Seed,
+		Pot:      bt.config.BaseType,
+		Branches: []SceneBranch{},
+		Leaves:   []SceneLeaf{},
+	}
+	bt.current = -1
+}
+
+// beginBranch makes the branch with the given ID current, and records it
+// growing from the previous one. It returns the ID of the previous branch,
+// to restore after.
+func (bt *Tree) beginBranch(id, x, y int, branchType BranchType, life int) int {
+	parent := bt.current
+	bt.current = id
+	if !bt.config.RecordScene {
+		return parent
+	}
+	bt.scene.Branches = append(bt.scene.Branches, SceneBranch{
+		ID:     bt.current,
+		Type:   branchType,
+		Parent: parent,
+		Start:  Point{x, y},
+		Life:   life,
+		Points: []Point{},
+	})
+	return parent
+}
+
+// recordStep adds a point to the current branch, and a leaf if one was drawn
+func (bt *Tree) recordStep(x, y int, leaf bool, glyph string, color string) {
+	if !bt.config.RecordScene {
+		return
+	}
+	b := &bt.scene.Branches[bt.current]
+	b.Points = append(b.Points, Point{x, y})
+	if leaf && y >= 0 && y < bt.scene.Height && x >= 0 && x < bt.scene.Width {
+		l := SceneLeaf{Point: Point{x, y}, Branch: bt.current, Glyph: glyph}
+		if c, ok := ANSIToRGB(color); ok {
+			l.Color = HexColor(c)
+		}
+		bt.scene.Leaves = append(bt.scene.Leaves, l)
+	}
+}
@@ -0,0 +1,110 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	for _, style := range []DrawStyle{StyleText, StyleBraille} {
+		t.Run(string(style), func(t *testing.T) {
+			config := DefaultConfig()
+			config.Seed = 1
+			config.Style = style
+			config.RecordScene = true
+			tree := NewTree(config)
+			if err := tree.GrowWith(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := tree.WriteJSON(&out); err != nil {
+				t.Fatal(err)
+			}
+			var scene Scene
+			if err := json.Unmarshal(out.Bytes(), &scene); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&scene, tree.Scene()) {
+				t.Fatal("scene doesn't survive a round trip through JSON")
+			}
+
+			scale := 1
+			if style == StyleBraille {
+				scale = 2
+			}
+			if scene.Width != config.Width*scale || scene.Height != config.Height*scale {
+				t.Errorf("scene is %dx%d on a %dx%d canvas", scene.Width, scene.Height, config.Width, config.Height)
+			}
+			if len(scene.Branches) == 0 || scene.Branches[0].Type != Trunk || scene.Branches[0].Parent != -1 {
+				t.Fatalf("scene doesn't start with the trunk: %+v", scene.Branches[:min(1, len(scene.Branches))])
+			}
+			for i, b := range scene.Branches {
+				if b.ID != i || b.Parent >= i {
+					t.Errorf("branch %d has ID %d and parent %d", i, b.ID, b.Parent)
+				}
+			}
+			for _, l := range scene.Leaves {
+				if l.X < 0 || l.Y < 0 || l.X >= scene.Width || l.Y >= scene.Height {
+					t.Errorf("leaf at %v is off the grid", l.Point)
+				}
+			}
+			if len(scene.Leaves) == 0 {
+				t.Error("scene has no leaves")
+			}
+		})
+	}
+}
+
+func TestSceneOptIn(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	tree := NewTree(config)
+	if err := tree.Grow(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if scene := tree.Scene(); len(scene.Branches) != 0 || len(scene.Leaves) != 0 {
+		t.Errorf("scene recorded without RecordScene: %d branches, %d leaves", len(scene.Branches), len(scene.Leaves))
+	}
+	if err := tree.WriteJSON(io.Discard); err == nil {
+		t.Error("WriteJSON succeeded without a recorded scene")
+	}
+
+	// Branch IDs are the same either way
+	recordedConfig := *config
+	recordedConfig.RecordScene = true
+	recorded := NewTree(&recordedConfig)
+	for _, bt := range []*Tree{tree, recorded} {
+		if err := bt.Start(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for more := true; more; {
+		var got, want []DrawnCell
+		got, more = tree.Step()
+		want, _ = recorded.Step()
+		if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(tree.Pending(), recorded.Pending()) {
+			t.Fatalf("step %d differs with RecordScene", tree.Steps())
+		}
+	}
+}
+
+func TestBranchTypeText(t *testing.T) {
+	for _, bt := range []BranchType{Trunk, ShootLeft, ShootRight, Dying, Dead} {
+		text, err := bt.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got BranchType
+		if err := got.UnmarshalText(text); err != nil || got != bt {
+			t.Errorf("%s came back as %v, %v", text, got, err)
+		}
+	}
+	var bt BranchType
+	if err := bt.UnmarshalText([]byte("root")); err == nil {
+		t.Error("unknown branch type was accepted")
+	}
+}
@@ -0,0 +1,110 @@
+package bonsai
+
+import (
+	"context"
+	"time"
+)
+
+// defaultFPS is how often a scheduled live tree is painted when only its
+// Duration is given
+const defaultFPS = 30
+
+// scheduled reports whether live growth is paced by the scheduler rather
+// than by sleeping after every step
+func (bt *Tree) scheduled() bool {
+	return bt.config.FPS > 0 || bt.config.Duration > 0
+}
+
+// growScheduled grows the whole tree up front, then plays its steps back to
+// r: one step every TimeStep, or spread evenly over Duration, painted at
+// most FPS times a second. Steps that fall due between two paints go out
+// together in one frame, so fast growth costs no more to draw than slow.
+func (bt *Tree) growScheduled(ctx context.Context, r Renderer) error {
+	rec := &frameRecorder{}
+	if err := bt.grow(ctx, rec, false); err != nil {
+		return err
+	}
+	frames := rec.frames // The pot first and the message last
+
+	// The first frame shows at once and the last one at the end
+	stepTime := time.Duration(bt.config.TimeStep * float64(time.Second))
+	if bt.config.Duration > 0 && len(frames) > 1 {
+		stepTime = time.Duration(bt.config.Duration * float64(time.Second) / float64(len(frames)-1))
+	}
+	fps := bt.config.FPS
+	if fps <= 0 {
+		fps = defaultFPS
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / fps))
+	defer ticker.Stop()
+
+	start := time.Now()
+	shown := 0
+	for {
+		due := len(frames)
+		if stepTime > 0 {
+			due = min(int(time.Since(start)/stepTime)+1, len(frames))
+		}
+		if due > shown {
+			if err := rec.play(r, frames[shown:due]); err != nil {
+				return err
+			}
+			shown = due
+		}
+		if shown == len(frames) {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// cellUpdate is a cell drawn at a position
+type cellUpdate struct {
+	x, y int
+	cell Cell
+}
+
+// frameRecorder is a Renderer that keeps the cells drawn in every frame, so
+// they can be played back later
+type frameRecorder struct {
+	frames [][]cellUpdate
+	open   []cellUpdate // Cells of the frame being drawn
+}
+
+// BeginFrame starts a frame
+func (f *frameRecorder) BeginFrame() error {
+	f.open = nil
+	return nil
+}
+
+// SetCell records the cell
+func (f *frameRecorder) SetCell(x, y int, cell Cell) error {
+	f.open = append(f.open, cellUpdate{x, y, cell})
+	return nil
+}
+
+// EndFrame keeps the frame
+func (f *frameRecorder) EndFrame() error {
+	f.frames = append(f.frames, f.open)
+	f.open = nil
+	return nil
+}
+
+// play draws frames to r as a single frame
+func (f *frameRecorder) play(r Renderer, frames [][]cellUpdate) error {
+	if err := r.BeginFrame(); err != nil {
+		return err
+	}
+	for _, frame := range frames {
+		for _, u := range frame {
+			if err := r.SetCell(u.x, u.y, u.cell); err != nil {
+				return err
+			}
+		}
+	}
+	return r.EndFrame()
+}
@@ -0,0 +1,84 @@
+package bonsai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// scheduledConfig is a live tree paced by the scheduler
+func scheduledConfig(fps, duration float64) *Config {
+	config := DefaultConfig()
+	config.Seed = 7
+	config.Live = true
+	config.FPS = fps
+	config.Duration = duration
+	return config
+}
+
+func TestGrowScheduled(t *testing.T) {
+	tests := []struct {
+		name                 string
+		fps, duration        float64
+		minFrames, maxFrames int
+	}{
+		{"one frame when steps take no time", 10, 0, 1, 1},
+		{"paints at most fps times a second", 20, 0.25, 2, 8},
+		{"default fps", 0, 0.1, 2, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := scheduledConfig(tt.fps, tt.duration)
+			if tt.duration == 0 {
+				config.TimeStep = 0
+			}
+			tree := NewTree(config)
+			var b Buffer
+			start := time.Now()
+			if err := tree.GrowWith(context.Background(), &b); err != nil {
+				t.Fatal(err)
+			}
+			elapsed := time.Since(start)
+			if b.Frames() < tt.minFrames || b.Frames() > tt.maxFrames {
+				t.Errorf("painted %d frames, want %d to %d", b.Frames(), tt.minFrames, tt.maxFrames)
+			}
+			want := time.Duration(tt.duration * float64(time.Second))
+			if elapsed < want || elapsed > want+time.Second {
+				t.Errorf("took %v, want about %v", elapsed, want)
+			}
+
+			// The frames add up to the finished tree
+			rendered := tree.Snapshot()
+			width, height := rendered.Size()
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					if got, want := b.Cell(x, y), rendered.Cell(x, y); got != want {
+						t.Fatalf("cell %d,%d played back as %+v, want %+v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGrowScheduledCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var b Buffer
+	err := NewTree(scheduledConfig(30, 10)).GrowWith(ctx, &b)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GrowWith() = %v, want %v", err, context.Canceled)
+	}
+	if b.Frames() != 0 {
+		t.Errorf("painted %d frames of a tree that was cancelled before growing", b.Frames())
+	}
+}
+
+func TestValidatePacing(t *testing.T) {
+	for _, config := range []*Config{scheduledConfig(-1, 0), scheduledConfig(0, -1)} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("fps %v and duration %v validated", config.FPS, config.Duration)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package bonsai
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// Shading says how colors are picked from the palette
+type Shading string
+
+const (
+	ShadingRandom   Shading = "random"   // A random color of the part for every step (default)
+	ShadingGradient Shading = "gradient" // Colors blended by age and height
+)
+
+// ParseShading checks a shading given by name
+func ParseShading(s string) (Shading, bool) {
+	switch shading := Shading(strings.ToLower(s)); shading {
+	case ShadingRandom, ShadingGradient:
+		return shading, true
+	}
+	return "", false
+}
+
+// gradient runs from the darkest to the lightest color of a palette part
+type gradient struct {
+	dark, light color.RGBA
+}
+
+// newGradient spans the colors of the given sets
+func newGradient(sets ...weightedSet) gradient {
+	var g gradient
+	darkest, lightest := 101.0, -1.0
+	for _, set := range sets {
+		for _, code := range set.items {
+			c, ok := ANSIToRGB(code)
+			if !ok {
+				continue
+			}
+			l := toLab(c)[0]
+			if l < darkest {
+				g.dark, darkest = c, l
+			}
+			if l > lightest {
+				g.light, lightest = c, l
+			}
+		}
+	}
+	return g
+}
+
+// at returns the escape code of the color a fraction t of the way from
+// dark to light
+func (g gradient) at(t float64) string {
+	t = min(max(t, 0), 1)
+	mix := func(a, b uint8) int {
+		return int(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", mix(g.dark.R, g.light.R), mix(g.dark.G, g.light.G), mix(g.dark.B, g.light.B))
+}
+
+// shade returns the color of a step in the gradient shading, or color as
+// it is otherwise. Branches get lighter with age, from the dark bark at the
+// base to the tips, and leaves get lighter the higher they are.
+func (bt *Tree) shade(color string, branchType BranchType, leaf bool, age, y int) string {
+	if !bt.config.UseColors || bt.config.Shading != ShadingGradient {
+		return color
+	}
+	switch {
+	case leaf && branchType == Dead:
+		return bt.palette.deadFoliage.at(1 - float64(y)/float64(bt.config.Height*bt.scale()))
+	case leaf:
+		return bt.palette.foliage.at(1 - float64(y)/float64(bt.config.Height*bt.scale()))
+	}
+	return bt.palette.bark.at(float64(age) / float64(max(bt.config.LifeStart, 1)))
+}
@@ -0,0 +1,101 @@
+package bonsai
+
+import (
+	"context"
+	"image/color"
+	"testing"
+)
+
+func TestParseShading(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Shading
+		ok   bool
+	}{
+		{"random", ShadingRandom, true},
+		{"Gradient", ShadingGradient, true},
+		{"smooth", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseShading(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseShading(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	set, err := parseColorSet([]string{"10:9", "28", "#ffffff:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newGradient(set)
+	if want := (gradient{color.RGBA{0x00, 0x87, 0x00, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}}); g != want {
+		t.Errorf("newGradient() = %v, want %v", g, want)
+	}
+
+	g = gradient{color.RGBA{0, 0, 0, 0xff}, color.RGBA{200, 100, 50, 0xff}}
+	tests := []struct {
+		t    float64
+		want string
+	}{
+		{0, "\033[38;2;0;0;0m"},
+		{0.5, "\033[38;2;100;50;25m"},
+		{1, "\033[38;2;200;100;50m"},
+		{-1, "\033[38;2;0;0;0m"},     // Clamped to dark
+		{2, "\033[38;2;200;100;50m"}, // Clamped to light
+	}
+	for _, tt := range tests {
+		if got := g.at(tt.t); got != tt.want {
+			t.Errorf("at(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestGradientShading(t *testing.T) {
+	grow := func(shading Shading) *Tree {
+		config := DefaultConfig()
+		config.Seed = 6
+		config.Shading = shading
+		config.RecordScene = true
+		tree := NewTree(config)
+		if err := tree.Grow(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+	random, gradient := grow(ShadingRandom), grow(ShadingGradient)
+	if random.Snapshot().String() != grow("").Snapshot().String() {
+		t.Error("random shading changed the tree")
+	}
+	if random.Snapshot().String() != gradient.Snapshot().String() {
+		t.Error("gradient shading changed the shape of the tree")
+	}
+
+	// Leaves get lighter the higher they are, on dead branches and the rest
+	scene := gradient.Scene()
+	if len(scene.Leaves) == 0 {
+		t.Fatal("tree has no leaves")
+	}
+	lightest := make(map[bool]map[int]float64) // By dead, then row
+	for _, leaf := range scene.Leaves {
+		c, err := ParseHexColor(leaf.Color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dead := scene.Branches[leaf.Branch].Type == Dead
+		if lightest[dead] == nil {
+			lightest[dead] = make(map[int]float64)
+		}
+		lightest[dead][leaf.Y] = max(lightest[dead][leaf.Y], toLab(c)[0])
+	}
+	for dead, rows := range lightest {
+		for y, l := range rows {
+			for below, lb := range rows {
+				if below > y && lb > l+0.5 {
+					t.Errorf("dead %v: leaves on row %d are lighter than on row %d", dead, below, y)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package bonsai
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SVGOptions controls how a tree is laid out as SVG. Zero values fall back
+// to the defaults below.
+type SVGOptions struct {
+	FontSize   float64 // Font size in px, default 16
+	FontFamily string  // CSS font stack, default a monospace stack
+	Background string  // CSS background color, empty for transparent
+	Foreground string  // Color for cells without one, default #e5e5e5
+}
+
+func (o SVGOptions) withDefaults() SVGOptions {
+	if o.FontSize <= 0 {
+		o.FontSize = 16
+	}
+	if o.FontFamily == "" {
+		o.FontFamily = "'DejaVu Sans Mono', Menlo, Consolas, monospace"
+	}
+	if o.Foreground == "" {
+		o.Foreground = HexColor(ansiBasic[7])
+	}
+	return o
+}
+
+// WriteSVG draws the contents of b as a scalable SVG image, with every glyph
+// placed as its own <text> element on a monospace grid
+func WriteSVG(w io.Writer, b *Buffer, opts SVGOptions) error {
+	opts = opts.withDefaults()
+	cellW := opts.FontSize * 0.6
+	cellH := opts.FontSize * 1.2
+	width, height := b.Size()
+	svgW := float64(width) * cellW
+	svgH := float64(height) * cellH
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %[1]s %[2]s\">\n",
+		svgNum(svgW), svgNum(svgH))
+	if opts.Background != "" {
+		fmt.Fprintf(bw, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", xmlAttr(opts.Background))
+	}
+	fmt.Fprintf(bw, "<g font-family=\"%s\" font-size=\"%g\" fill=\"%s\" xml:space=\"preserve\">\n",
+		xmlAttr(opts.FontFamily), opts.FontSize, xmlAttr(opts.Foreground))
+	for y := 0; y < height; y++ {
+		// Baseline sits a little above the bottom of the cell
+		baseline := float64(y)*cellH + opts.FontSize
+		for x := 0; x < width; x++ {
+			cell := b.Cell(x, y)
+			if cell.Blank() {
+				continue
+			}
+			if c, ok := ANSIToRGB(cell.Background); ok {
+				writeSVGRect(bw, float64(x)*cellW, float64(y)*cellH, cellW, cellH, HexColor(c))
+			}
+			if top, bottom, ok := blockHalves(cell.Glyph); ok {
+				fill := opts.Foreground
+				if c, ok := ANSIToRGB(cell.Color); ok {
+					fill = HexColor(c)
+				}
+				if top {
+					writeSVGRect(bw, float64(x)*cellW, float64(y)*cellH, cellW, cellH/2, fill)
+				}
+				if bottom {
+					writeSVGRect(bw, float64(x)*cellW, (float64(y)+0.5)*cellH, cellW, cellH/2, fill)
+				}
+				continue
+			}
+			fmt.Fprintf(bw, "<text x=\"%s\" y=\"%s\"", svgNum(float64(x)*cellW), svgNum(baseline))
+			if c, ok := ANSIToRGB(cell.Color); ok {
+				fmt.Fprintf(bw, " fill=\"%s\"", HexColor(c))
+			}
+			writeSVGAttrs(bw, cell.Attrs)
+			bw.WriteString(">")
+			xml.EscapeText(bw, []byte(cell.Glyph))
+			bw.WriteString("</text>\n")
+		}
+	}
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}
+
+// writeSVGAttrs writes the presentation attributes for text attributes
+func writeSVGAttrs(w io.Writer, attrs Attr) {
+	if attrs&AttrBold != 0 {
+		io.WriteString(w, " font-weight=\"bold\"")
+	}
+	if attrs&AttrDim != 0 {
+		io.WriteString(w, " fill-opacity=\"0.5\"")
+	}
+	if attrs&AttrItalic != 0 {
+		io.WriteString(w, " font-style=\"italic\"")
+	}
+}
+
+// writeSVGRect writes a filled rectangle, used for backgrounds and blocks
+func writeSVGRect(w io.Writer, x, y, width, height float64, fill string) {
+	fmt.Fprintf(w, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+		svgNum(x), svgNum(y), svgNum(width), svgNum(height), xmlAttr(fill))
+}
+
+// svgNum formats a coordinate with at most two decimals
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// xmlAttr escapes s for use inside a double-quoted XML attribute
+func xmlAttr(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
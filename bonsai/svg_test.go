@@ -0,0 +1,81 @@
+package bonsai
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteSVG(t *testing.T) {
+	var b Buffer
+	b.SetCell(0, 0, Cell{Glyph: "<", Width: 1, Color: ColorRed, Attrs: AttrBold})
+	b.SetCell(1, 0, blankCell)
+	b.SetCell(2, 1, Cell{Glyph: upperHalf, Width: 1, Color: ColorGreen, Background: ansiBackground(ColorRed)})
+
+	var out bytes.Buffer
+	if err := WriteSVG(&out, &b, SVGOptions{Background: "#000"}); err != nil {
+		t.Fatal(err)
+	}
+	svg := out.String()
+
+	// It must be well-formed XML
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+	}
+	for _, want := range []string{
+		`width="28.8" height="38.4"`,                     // 3x2 cells of 9.6x19.2
+		`<rect width="100%" height="100%" fill="#000"/>`, // Background
+		`<text x="0" y="16" fill="#cd0000" font-weight="bold">&lt;</text>`,
+		`fill="#00cd00"`, // The top half of the block
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %s:\n%s", want, svg)
+		}
+	}
+	if strings.Count(svg, "<text") != 1 {
+		t.Errorf("blank cells and blocks were drawn as text:\n%s", svg)
+	}
+}
+
+func TestANSIToRGB(t *testing.T) {
+	tests := []struct {
+		code string
+		want color.RGBA
+		ok   bool
+	}{
+		{ColorGreen, color.RGBA{0x00, 0xcd, 0x00, 0xff}, true},
+		{ColorBrightYellow, color.RGBA{0xff, 0xff, 0x00, 0xff}, true},
+		{"\033[38;5;16m", color.RGBA{0x00, 0x00, 0x00, 0xff}, true},
+		{"\033[38;5;208m", color.RGBA{0xff, 0x87, 0x00, 0xff}, true},
+		{"\033[38;5;244m", color.RGBA{0x80, 0x80, 0x80, 0xff}, true},
+		{"\033[38;5;256m", color.RGBA{}, false},
+		{"", color.RGBA{}, false},
+		{ColorReset, color.RGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ANSIToRGB(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ANSIToRGB(%q) = %v, %v, want %v, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	for in, want := range map[string]string{"fff": "#ffffff", "#1E1E2E": "#1e1e2e", "#abc": "#aabbcc"} {
+		c, err := ParseHexColor(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := HexColor(c); got != want {
+			t.Errorf("HexColor(ParseHexColor(%q)) = %q, want %q", in, got, want)
+		}
+	}
+}
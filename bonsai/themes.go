@@ -0,0 +1,240 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Pot is the art drawn under the tree, top row first, with every row
+// centered under the trunk. Colors has a row of letters per line, one per
+// glyph: g for grass, s for soil, and anything else, or nothing, for the
+// pot itself. In the pixels style the pot is drawn as a filled shape,
+// covering every glyph but the spaces that have no letter.
+type Pot struct {
+	Lines  []string `json:"lines"`
+	Colors []string `json:"colors,omitempty"`
+}
+
+// validate checks that every line of the pot can be drawn
+func (p Pot) validate() error {
+	if len(p.Lines) == 0 {
+		return fmt.Errorf("has no lines")
+	}
+	for i, line := range p.Lines {
+		if !utf8.ValidString(line) || strings.ContainsFunc(line, unicode.IsControl) {
+			return fmt.Errorf("line %d contains control characters or invalid UTF-8", i+1)
+		}
+	}
+	return nil
+}
+
+// classicPots are the cbonsai pots, used when a config has none of its own
+var classicPots = []Pot{
+	{
+		Lines: []string{
+			":'^\"*o%.,~`'./~~~\\.~`'^\"*o%.,~:",
+			" \\                           / ",
+			"  \\_________________________/ ",
+			"   (^)                 (^)   ",
+		},
+		Colors: []string{
+			"pgggggggggggsssssssgggggggggggp",
+			" ppppppppppppppppppppppppppppp ",
+		},
+	},
+	{
+		Lines: []string{
+			":'^\"*o%../~~~\\.o%.,~`':",
+			" \\                   / ",
+			"  \\_________________/ ",
+			"   (^)          (^)   ",
+		},
+		Colors: []string{
+			"pgggggggsssssssgggggggg",
+			" ppppppppppppppppppppp ",
+		},
+	},
+}
+
+// GlyphTheme is a named look for the branches and the pot. Strokes left
+// empty keep the classic characters, and a theme without pots keeps the
+// classic ones.
+type GlyphTheme struct {
+	Name   string   `json:"name"`
+	Trunk  Strokes  `json:"trunk"`
+	Shoot  Strokes  `json:"shoot"`
+	Pots   []Pot    `json:"pots,omitempty"`   // For --base 1, 2 and so on
+	Leaves []string `json:"leaves,omitempty"` // Used unless leaves are given
+}
+
+// glyphThemes are the built-in themes, plain ASCII first
+var glyphThemes = []GlyphTheme{
+	{Name: "ascii"},
+	{
+		Name:  "light",
+		Trunk: Strokes{Flat: "─", UpLeft: "╲", Up: "│", UpRight: "╱"},
+		Shoot: Strokes{Flat: "─", UpLeft: "╲", Up: "│", UpRight: "╱", DownLeft: "╲", DownRight: "╱"},
+		Pots: []Pot{
+			{
+				Lines: []string{
+					classicPots[0].Lines[0],
+					" ╲                           ╱ ",
+					"  ╰─────────────────────────╯ ",
+					"   ╰┴╯                 ╰┴╯   ",
+				},
+				Colors: classicPots[0].Colors,
+			},
+			{
+				Lines: []string{
+					classicPots[1].Lines[0],
+					" ╲                   ╱ ",
+					"  ╰─────────────────╯ ",
+					"   ╰┴╯          ╰┴╯   ",
+				},
+				Colors: classicPots[1].Colors,
+			},
+		},
+	},
+	{
+		Name:  "heavy",
+		Trunk: Strokes{Flat: "━", UpLeft: "╲", Up: "┃", UpRight: "╱"},
+		Shoot: Strokes{Flat: "─", UpLeft: "╲", Up: "│", UpRight: "╱", DownLeft: "╲", DownRight: "╱"},
+		Pots: []Pot{
+			{
+				Lines: []string{
+					classicPots[0].Lines[0],
+					" ┃                           ┃ ",
+					" ┗━━━━━━━━━━━━━━━━━━━━━━━━━━━┛",
+					"   ┻━┻                 ┻━┻   ",
+				},
+				Colors: classicPots[0].Colors,
+			},
+			{
+				Lines: []string{
+					classicPots[1].Lines[0],
+					" ┃                   ┃ ",
+					" ┗━━━━━━━━━━━━━━━━━━━┛",
+					"   ┻━┻          ┻━┻   ",
+				},
+				Colors: classicPots[1].Colors,
+			},
+		},
+	},
+	{
+		Name:  "block",
+		Trunk: Strokes{Flat: "▀", UpLeft: "▚", Up: "█:3,▓", UpRight: "▞"},
+		Shoot: Strokes{Flat: "▀", UpLeft: "▚", Up: "▌", UpRight: "▞", DownLeft: "▚", DownRight: "▞"},
+		Pots: []Pot{
+			{
+				Lines: []string{
+					classicPots[0].Lines[0],
+					" ▜███████████████████████████▛ ",
+					"  ▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀ ",
+					"   ▝▀▘                 ▝▀▘   ",
+				},
+				Colors: classicPots[0].Colors,
+			},
+			{
+				Lines: []string{
+					classicPots[1].Lines[0],
+					" ▜███████████████████▛ ",
+					"  ▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀ ",
+					"   ▝▀▘          ▝▀▘   ",
+				},
+				Colors: classicPots[1].Colors,
+			},
+		},
+	},
+	{
+		// Needs a Nerd Font: powerline caps round off the pot, and the
+		// leaves are Font Awesome icons
+		Name:  "nerd-font",
+		Trunk: Strokes{Flat: "━", UpLeft: "╲", Up: "┃", UpRight: "╱"},
+		Shoot: Strokes{Flat: "─", UpLeft: "╲", Up: "│", UpRight: "╱", DownLeft: "╲", DownRight: "╱"},
+		Pots: []Pot{
+			{
+				Lines: []string{
+					classicPots[0].Lines[0],
+					" \ue0b6███████████████████████████\ue0b4 ",
+					"  \ue0b6█████████████████████████\ue0b4 ",
+					"   ▝▀▘                 ▝▀▘   ",
+				},
+				Colors: classicPots[0].Colors,
+			},
+			{
+				Lines: []string{
+					classicPots[1].Lines[0],
+					" \ue0b6███████████████████\ue0b4 ",
+					"  \ue0b6█████████████████\ue0b4 ",
+					"   ▝▀▘          ▝▀▘   ",
+				},
+				Colors: classicPots[1].Colors,
+			},
+		},
+		Leaves: []string{"\uf06c:3", "\uf18c", "&"},
+	},
+}
+
+// GlyphThemes returns the names of the built-in glyph themes
+func GlyphThemes() []string {
+	names := make([]string, len(glyphThemes))
+	for i, theme := range glyphThemes {
+		names[i] = theme.Name
+	}
+	return names
+}
+
+// LookupGlyphTheme returns the built-in glyph theme with the given name
+func LookupGlyphTheme(name string) (GlyphTheme, bool) {
+	for _, theme := range glyphThemes {
+		if strings.EqualFold(theme.Name, name) {
+			return theme, true
+		}
+	}
+	return GlyphTheme{}, false
+}
+
+// LoadGlyphTheme reads a glyph theme written as JSON, in the same layout
+// as GlyphTheme
+func LoadGlyphTheme(r io.Reader) (GlyphTheme, error) {
+	var theme GlyphTheme
+	if err := json.NewDecoder(r).Decode(&theme); err != nil {
+		return GlyphTheme{}, fmt.Errorf("reading glyph theme: %w", err)
+	}
+	if err := theme.validate(); err != nil {
+		return GlyphTheme{}, fmt.Errorf("invalid glyph theme: %w", err)
+	}
+	return theme, nil
+}
+
+// validate checks every glyph in the theme
+func (t GlyphTheme) validate() error {
+	if err := t.Trunk.validate(); err != nil {
+		return fmt.Errorf("trunk: %w", err)
+	}
+	if err := t.Shoot.validate(); err != nil {
+		return fmt.Errorf("shoot: %w", err)
+	}
+	for i, pot := range t.Pots {
+		if err := pot.validate(); err != nil {
+			return fmt.Errorf("pot %d %w", i+1, err)
+		}
+	}
+	if len(t.Leaves) > 0 {
+		return validateLeaves(t.Leaves)
+	}
+	return nil
+}
+
+// Apply sets the branch strokes and pots of config to the theme's. Leaves
+// are left alone; the theme's leaves are a suggestion for callers to use
+// when none were asked for.
+func (t GlyphTheme) Apply(config *Config) {
+	config.TrunkGlyphs = t.Trunk
+	config.ShootGlyphs = t.Shoot
+	config.Pots = t.Pots
+}
@@ -0,0 +1,99 @@
+package bonsai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLookupGlyphTheme(t *testing.T) {
+	names := GlyphThemes()
+	if len(names) == 0 || names[0] != "ascii" {
+		t.Fatalf("GlyphThemes() = %q, want ascii first", names)
+	}
+	for _, name := range names {
+		theme, ok := LookupGlyphTheme(strings.ToUpper(name))
+		if !ok || theme.Name != name {
+			t.Errorf("LookupGlyphTheme(%q) = %q, %v", strings.ToUpper(name), theme.Name, ok)
+		}
+		if err := theme.validate(); err != nil {
+			t.Errorf("theme %s: %v", name, err)
+		}
+	}
+	if _, ok := LookupGlyphTheme("gothic"); ok {
+		t.Error("unknown theme was found")
+	}
+}
+
+func TestThemePots(t *testing.T) {
+	// Pots line up with the classic ones row by row, so trees sit on them
+	// the same way, and colors cover every glyph of the rows they color
+	themes := append([]GlyphTheme{{Name: "classic", Pots: classicPots}}, glyphThemes...)
+	for _, theme := range themes {
+		for i, pot := range theme.Pots {
+			classic := classicPots[i]
+			if len(pot.Lines) != len(classic.Lines) || len(pot.Colors) != len(classic.Colors) {
+				t.Errorf("%s pot %d has %d lines and %d color rows, want %d and %d", theme.Name, i+1,
+					len(pot.Lines), len(pot.Colors), len(classic.Lines), len(classic.Colors))
+				continue
+			}
+			for row, line := range pot.Lines {
+				if got, want := StringWidth(line), StringWidth(classic.Lines[row]); got != want {
+					t.Errorf("%s pot %d row %d is %d wide, want %d: %q", theme.Name, i+1, row, got, want, line)
+				}
+				if row < len(pot.Colors) && len(pot.Colors[row]) != len(graphemes(line)) {
+					t.Errorf("%s pot %d row %d has %d colors for %d glyphs", theme.Name, i+1, row,
+						len(pot.Colors[row]), len(graphemes(line)))
+				}
+			}
+		}
+	}
+}
+
+func TestLoadGlyphTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"strokes", `{"name": "mine", "trunk": {"up": "#:2,H"}, "shoot": {"flat": "="}}`, false},
+		{"pots", `{"pots": [{"lines": ["~~~", "\\_/"], "colors": ["gsg"]}]}`, false},
+		{"leaves", `{"leaves": ["🌸", "&:2"]}`, false},
+		{"bad stroke", `{"trunk": {"up": "|,"}}`, true},
+		{"empty pot", `{"pots": [{"lines": []}]}`, true},
+		{"control characters", `{"pots": [{"lines": ["\u0007"]}]}`, true},
+		{"bad leaf", `{"leaves": [""]}`, true},
+		{"not JSON", `theme`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadGlyphTheme(strings.NewReader(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadGlyphTheme() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGlyphThemeApply(t *testing.T) {
+	for _, base := range []int{1, 2} {
+		config := DefaultConfig()
+		config.Seed = 2
+		config.BaseType = base
+		theme, _ := LookupGlyphTheme("heavy")
+		theme.Apply(config)
+		tree := NewTree(config)
+		if err := tree.Grow(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+		out := tree.Snapshot().String()
+		pot := theme.Pots[base-1].Lines
+		if !strings.Contains(out, strings.TrimSpace(pot[len(pot)-1])) {
+			t.Errorf("base %d isn't the heavy pot:\n%s", base, out)
+		}
+		rows := strings.Split(out, "\n")
+		if above := strings.Join(rows[:config.Height-len(pot)], "\n"); !strings.ContainsAny(above, "┃━") {
+			t.Errorf("trunk isn't drawn with heavy strokes:\n%s", out)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package bonsai
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Point represents a coordinate
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Tree represents the tree structure
+type Tree struct {
+	canvas   [][]Cell
+	config   *Config
+	branches int
+	shoots   int
+	rng      *rand.Rand
+	scene    Scene
+	current  int // Scene ID of the branch being grown
+	layout   messageLayout
+	glyphs   *glyphTable
+	palette  *paletteTable
+	rules    growthRules // Rules of the algorithm the tree grows by
+	looks    *rand.Rand  // Picks what the classic tree never rolled for, so themes don't change the shape
+	dots     [][]dot     // Raised dots or painted pixels, in the plotted styles
+
+	// Draw operations are forwarded here while the tree grows
+	ctx      context.Context
+	renderer Renderer
+	paced    bool // Sleep a time step after every step
+	err      error
+
+	// Growth state, see grow.go
+	stack   []branchFrame // Branches still growing, the one growing now on top
+	steps   int
+	growing bool        // Between start and finish
+	capture bool        // Keep drawn cells for Step
+	drawn   []DrawnCell // Cells drawn in the current Step
+}
+
+// NewTree creates a new bonsai tree sized to config.Width by config.Height
+func NewTree(config *Config) *Tree {
+	canvas := make([][]Cell, config.Height)
+	for i := range canvas {
+		canvas[i] = make([]Cell, config.Width)
+		for j := range canvas[i] {
+			canvas[i][j] = blankCell
+		}
+	}
+
+	bt := &Tree{
+		canvas: canvas,
+		config: config,
+		rules:  algorithms[config.algorithm()],
+	}
+	bt.layout = bt.layoutMessage()
+	bt.glyphs = newGlyphTable(config)
+	bt.palette = newPaletteTable(config)
+	return bt
+}
+
+// Config returns the configuration the tree was created with
+func (bt *Tree) Config() *Config {
+	return bt.config
+}
+
+// Size returns the width and height of everything the tree draws: the
+// canvas, and the message when it goes below the canvas
+func (bt *Tree) Size() (int, int) {
+	height := len(bt.canvas)
+	if len(bt.layout.lines) > 0 && bt.layout.box.Empty() {
+		height += 1 + len(bt.layout.lines) // A blank line, then the message
+	}
+	return bt.config.Width, height
+}
+
+// endFrame closes the current frame on the renderer and opens the next one
+func (bt *Tree) endFrame() {
+	if bt.renderer == nil || bt.err != nil {
+		return
+	}
+	if bt.err = bt.renderer.EndFrame(); bt.err == nil {
+		bt.err = bt.renderer.BeginFrame()
+	}
+}
+
+// sleep waits one live time step, or until the context is cancelled
+func (bt *Tree) sleep() {
+	if bt.err != nil {
+		return
+	}
+	timer := time.NewTimer(time.Duration(bt.config.TimeStep * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-bt.ctx.Done():
+		bt.err = bt.ctx.Err()
+	}
+}
+
+// SetPixel draws a glyph string at the given position and passes it on to
+// the renderer. Every grapheme cluster takes up as many columns as it is
+// wide, and a wide glyph that does not fit on the canvas is left out.
+func (bt *Tree) SetPixel(x, y int, glyph string, color string) {
+	bt.draw(x, y, glyph, Cell{Color: color})
+}
+
+// draw is SetPixel with the rest of the cell given by style: a glyph gets
+// the colors, attributes, owner and Z of style, and is left out where it
+// would cover a cell with a higher Z
+func (bt *Tree) draw(x, y int, glyph string, style Cell) {
+	if y < 0 || y >= len(bt.canvas) {
+		return
+	}
+	for _, cluster := range graphemes(glyph) {
+		width := clusterWidth(cluster)
+		if width == 0 {
+			continue // A stray mark with nothing to attach to
+		}
+		if x >= 0 && x+width <= len(bt.canvas[y]) && !bt.covered(x, y, width, style.Z) {
+			bt.clearWide(x, y, width)
+			cell := style
+			cell.Glyph, cell.Width = cluster, width
+			bt.putCell(x, y, cell)
+			cell.Glyph, cell.Width = "", 0
+			for i := 1; i < width; i++ {
+				bt.putCell(x+i, y, cell)
+			}
+		}
+		x += width
+	}
+}
+
+// covered reports whether any of width columns at x shows something with a
+// Z higher than z
+func (bt *Tree) covered(x, y, width, z int) bool {
+	for _, cell := range bt.canvas[y][x : x+width] {
+		if cell.Z > z && (cell.Width == 0 || !cell.Blank()) {
+			return true
+		}
+	}
+	return false
+}
+
+// clearWide blanks any wide glyph that drawing width columns at x would cut
+// in half, so no half glyph is ever left on the canvas
+func (bt *Tree) clearWide(x, y, width int) {
+	row := bt.canvas[y]
+	left := x
+	for left > 0 && row[left].Width == 0 {
+		left--
+	}
+	for i := left; i < x; i++ {
+		bt.putCell(i, y, blankCell)
+	}
+	for i := x + width; i < len(row) && row[i].Width == 0; i++ {
+		bt.putCell(i, y, blankCell)
+	}
+}
+
+// putCell stores a single cell and passes it on to the renderer
+func (bt *Tree) putCell(x, y int, cell Cell) {
+	bt.canvas[y][x] = cell
+	if bt.capture {
+		bt.drawn = append(bt.drawn, DrawnCell{x, y, cell})
+	}
+	if bt.renderer != nil && bt.err == nil {
+		bt.err = bt.renderer.SetCell(x, y, cell)
+	}
+}
+
+// GetDeltas calculates movement deltas based on branch type and age, by the
+// rules of the tree's algorithm
+func (bt *Tree) GetDeltas(branchType BranchType, life, age int) (int, int) {
+	return bt.rules.deltas(bt, branchType, life, age)
+}
+
+// ChooseChar selects the appropriate character for the branch
+func (bt *Tree) ChooseChar(branchType BranchType, life, dx, dy int) string {
+	if life < 4 {
+		branchType = Dying
+	}
+
+	switch branchType {
+	case Trunk:
+		strokes := &bt.glyphs.trunk
+		if dy == 0 {
+			return strokes.flat.pick(bt.looks)
+		} else if dx < 0 {
+			return strokes.upLeft.pick(bt.looks)
+		} else if dx == 0 {
+			return strokes.up.pick(bt.looks)
+		} else {
+			return strokes.upRight.pick(bt.looks)
+		}
+
+	case ShootLeft, ShootRight:
+		strokes := &bt.glyphs.shoot
+		if dy > 0 && branchType == ShootLeft {
+			return strokes.downLeft.pick(bt.looks)
+		} else if dy > 0 {
+			return strokes.downRight.pick(bt.looks)
+		} else if dy == 0 {
+			return strokes.flat.pick(bt.looks)
+		} else if dx < 0 {
+			return strokes.upLeft.pick(bt.looks)
+		} else if dx == 0 {
+			return strokes.up.pick(bt.looks)
+		} else {
+			return strokes.upRight.pick(bt.looks)
+		}
+
+	case Dying:
+		return bt.glyphs.dying.leaf(bt.rng)
+
+	case Dead:
+		return bt.glyphs.dead.leaf(bt.rng)
+	}
+
+	return "?"
+}
+
+// Branch grows a branch from x, y, along with every branch that sprouts
+// from it, before returning
+func (bt *Tree) Branch(x, y int, branchType BranchType, life int) {
+	floor := len(bt.stack)
+	bt.push(x, y, branchType, life)
+	for bt.advance(floor) {
+	}
+}
+
+// stepStyle returns the owner and stacking of a growth step: leaves go in
+// front of or behind branches as LeafLayer says, and otherwise whatever is
+// drawn last is on top
+func (bt *Tree) stepStyle(branchType BranchType, leaf bool, color string) Cell {
+	style := Cell{Color: color, Owner: OwnerTrunk}
+	switch {
+	case leaf:
+		style.Owner = OwnerLeaf
+		switch bt.config.LeafLayer {
+		case LeavesFront:
+			style.Z = zFront
+		case LeavesBehind:
+			style.Z = zBehind
+		}
+	case branchType == ShootLeft || branchType == ShootRight:
+		style.Owner = OwnerShoot
+	}
+	return style
+}
+
+// DrawBase draws the base of the tree
+func (bt *Tree) DrawBase() {
+	pot, ok := bt.pot()
+	if !ok {
+		return
+	}
+
+	baseY := bt.config.Height - 1
+	centerX := bt.layout.centerX
+	baseColor := bt.GetBaseColor()
+
+	for row, line := range pot.Lines {
+		y := baseY - (len(pot.Lines) - 1 - row)
+		colors := ""
+		if row < len(pot.Colors) {
+			colors = pot.Colors[row]
+		}
+
+		x := centerX - StringWidth(line)/2
+		for i, glyph := range graphemes(line) {
+			width := clusterWidth(glyph)
+			color, letter := baseColor, byte(' ')
+			if i < len(colors) {
+				letter = colors[i]
+			}
+			switch letter {
+			case 'g':
+				color = ""
+				if bt.config.UseColors {
+					color = bt.palette.grass.pick(bt.looks)
+				}
+			case 's':
+				color = ""
+				if bt.config.UseColors {
+					color = bt.palette.soil.pick(bt.looks)
+				}
+			}
+			if bt.config.Style == StylePixels && (glyph != " " || letter != ' ') {
+				glyph = strings.Repeat(fullBlock, width)
+			}
+			bt.draw(x, y, glyph, Cell{Color: color, Owner: OwnerPot})
+			x += width
+		}
+	}
+}
+
+// pot returns the pot picked by BaseType, if there is one
+func (bt *Tree) pot() (Pot, bool) {
+	pots := bt.config.pots()
+	if bt.config.BaseType < 1 || bt.config.BaseType > len(pots) {
+		return Pot{}, false
+	}
+	return pots[bt.config.BaseType-1], true
+}
+
+// Grow generates the complete tree. In live mode every step is drawn to w
+// as it happens, using cursor movement escapes; otherwise w is not written
+// and the finished tree can be written with Render.
+func (bt *Tree) Grow(ctx context.Context, w io.Writer) error {
+	if !bt.config.Live {
+		return bt.GrowWith(ctx, nil)
+	}
+	return bt.GrowWith(ctx, NewTerminalRenderer(w))
+}
+
+// GrowWith generates the complete tree, sending every draw operation to r.
+// In live mode each step is paced by TimeStep, or by FPS and Duration when
+// either is set. r may be nil.
+func (bt *Tree) GrowWith(ctx context.Context, r Renderer) error {
+	if bt.config.Live && r != nil && bt.scheduled() {
+		return bt.growScheduled(ctx, r)
+	}
+	return bt.grow(ctx, r, bt.config.Live)
+}
+
+// grow generates the complete tree, sleeping a time step after every step
+// if paced is set
+func (bt *Tree) grow(ctx context.Context, r Renderer, paced bool) error {
+	bt.start(ctx, r, paced)
+	for bt.advance(0) {
+	}
+	bt.finish()
+	return bt.err
+}
+
+// Render writes the finished tree to w, one line per canvas row
+func (bt *Tree) Render(ctx context.Context, w io.Writer) error {
+	return bt.RenderWith(ctx, NewTextRenderer(w, bt.config.UseColors))
+}
+
+// RenderWith draws the whole finished tree to r as a single frame
+func (bt *Tree) RenderWith(ctx context.Context, r Renderer) error {
+	if err := r.BeginFrame(); err != nil {
+		return err
+	}
+	for y := 0; y < len(bt.canvas); y++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for x := 0; x < len(bt.canvas[y]); x++ {
+			if err := r.SetCell(x, y, bt.canvas[y][x]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := bt.drawMessageBelow(r); err != nil {
+		return err
+	}
+	return r.EndFrame()
+}
+
+// Snapshot renders the finished tree into a new Buffer
+func (bt *Tree) Snapshot() *Buffer {
+	b := &Buffer{}
+	bt.RenderWith(context.Background(), b) // A Buffer never fails
+	return b
+}
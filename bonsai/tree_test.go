@@ -0,0 +1,59 @@
+package bonsai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// failingRenderer fails once it has been sent n cells
+type failingRenderer struct {
+	n int
+}
+
+var errRender = errors.New("render failed")
+
+func (f *failingRenderer) BeginFrame() error { return nil }
+func (f *failingRenderer) EndFrame() error   { return nil }
+
+func (f *failingRenderer) SetCell(x, y int, cell Cell) error {
+	if f.n--; f.n < 0 {
+		return errRender
+	}
+	return nil
+}
+
+func TestRenderKeepsGrowthError(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	tree := NewTree(config)
+	if err := tree.GrowWith(context.Background(), &failingRenderer{n: 50}); !errors.Is(err, errRender) {
+		t.Fatalf("GrowWith() = %v, want %v", err, errRender)
+	}
+	if err := tree.RenderWith(context.Background(), &Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Err(); !errors.Is(err, errRender) {
+		t.Errorf("Err() after rendering = %v, want %v", err, errRender)
+	}
+}
+
+func TestRenderWithError(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	config.Message = "below"
+	config.MessagePos = MessageBelow
+	tree := NewTree(config)
+	if err := tree.GrowWith(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	cells := config.Width * config.Height
+	for _, n := range []int{0, cells - 1, cells + 2} {
+		if err := tree.RenderWith(context.Background(), &failingRenderer{n: n}); !errors.Is(err, errRender) {
+			t.Errorf("RenderWith() failing after %d cells = %v, want %v", n, err, errRender)
+		}
+	}
+	if err := tree.Err(); err != nil {
+		t.Errorf("Err() = %v after render errors", err)
+	}
+}
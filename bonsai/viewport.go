@@ -0,0 +1,52 @@
+package bonsai
+
+// Place returns a Renderer that puts a canvas of width by height cells into
+// a viewport of viewWidth by viewHeight, such as a terminal of another size
+// than the tree was grown for, and passes the cells on to r. The canvas is
+// centered across the viewport and stands on its bottom edge, like the pot
+// does. Whatever doesn't fit is cut off, from the top and both sides.
+// Tree.Size gives the size to place, so a message below the canvas comes
+// along with it.
+func Place(r Renderer, width, height, viewWidth, viewHeight int) Renderer {
+	if width == viewWidth && height == viewHeight {
+		return r
+	}
+	return &viewport{
+		r:      r,
+		dx:     (viewWidth - width) / 2,
+		dy:     viewHeight - height,
+		width:  viewWidth,
+		height: viewHeight,
+	}
+}
+
+// viewport moves cells into view on their way to another renderer
+type viewport struct {
+	r             Renderer
+	dx, dy        int // Position of the canvas in the viewport
+	width, height int
+}
+
+// BeginFrame starts a frame
+func (v *viewport) BeginFrame() error {
+	return v.r.BeginFrame()
+}
+
+// SetCell moves the cell into the viewport and passes it on if it is in
+// view. A wide glyph cut in half at an edge is blanked instead.
+func (v *viewport) SetCell(x, y int, cell Cell) error {
+	x += v.dx
+	y += v.dy
+	if x < 0 || x >= v.width || y < 0 || y >= v.height {
+		return nil
+	}
+	if (cell.Width == 0 && x == 0 && v.dx < 0) || x+cell.Width > v.width {
+		cell = blankCell
+	}
+	return v.r.SetCell(x, y, cell)
+}
+
+// EndFrame ends the frame
+func (v *viewport) EndFrame() error {
+	return v.r.EndFrame()
+}
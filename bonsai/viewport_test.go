@@ -0,0 +1,92 @@
+package bonsai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPlace(t *testing.T) {
+	wide := Cell{Glyph: "木", Width: 2}
+	cont := Cell{Width: 0}
+	tests := []struct {
+		name          string
+		width, height int
+		view          [2]int
+		cells         map[Point]Cell
+		want          map[Point]Cell
+	}{
+		{
+			name:  "same size",
+			width: 4, height: 2, view: [2]int{4, 2},
+			cells: map[Point]Cell{{1, 1}: {Glyph: "a", Width: 1}},
+			want:  map[Point]Cell{{1, 1}: {Glyph: "a", Width: 1}},
+		},
+		{
+			name:  "bigger view",
+			width: 4, height: 2, view: [2]int{8, 5},
+			cells: map[Point]Cell{{0, 0}: {Glyph: "a", Width: 1}},
+			want:  map[Point]Cell{{2, 3}: {Glyph: "a", Width: 1}},
+		},
+		{
+			name:  "smaller view cuts off the top",
+			width: 4, height: 4, view: [2]int{4, 2},
+			cells: map[Point]Cell{{0, 0}: {Glyph: "a", Width: 1}, {0, 3}: {Glyph: "b", Width: 1}},
+			want:  map[Point]Cell{{0, 1}: {Glyph: "b", Width: 1}},
+		},
+		{
+			name:  "wide glyph cut at the left",
+			width: 6, height: 1, view: [2]int{4, 1},
+			cells: map[Point]Cell{{0, 0}: wide, {1, 0}: cont},
+			want:  map[Point]Cell{{0, 0}: blankCell},
+		},
+		{
+			name:  "wide glyph cut at the right",
+			width: 6, height: 1, view: [2]int{4, 1},
+			cells: map[Point]Cell{{4, 0}: wide, {5, 0}: cont},
+			want:  map[Point]Cell{{3, 0}: blankCell},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Buffer
+			r := Place(&b, tt.width, tt.height, tt.view[0], tt.view[1])
+			for p, cell := range tt.cells {
+				if err := r.SetCell(p.X, p.Y, cell); err != nil {
+					t.Fatal(err)
+				}
+			}
+			width, height := b.Size()
+			if width > tt.view[0] || height > tt.view[1] {
+				t.Errorf("drew %dx%d into a %dx%d view", width, height, tt.view[0], tt.view[1])
+			}
+			for p, want := range tt.want {
+				if got := b.Cell(p.X, p.Y); got != want {
+					t.Errorf("cell %v = %+v, want %+v", p, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceMessageBelow(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	config.Width, config.Height = 60, 12
+	config.Message = "HI"
+	config.MessagePos = MessageBelow
+	tree := NewTree(config)
+	width, height := tree.Size()
+	if width != 60 || height != 14 {
+		t.Fatalf("size %dx%d, want 60x14", width, height)
+	}
+
+	var b Buffer
+	if err := tree.GrowWith(context.Background(), Place(&b, width, height, 80, 24)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	if len(lines) != 24 || strings.TrimSpace(lines[23]) != "HI" {
+		t.Errorf("message not on the last line of the view:\n%s", b.String())
+	}
+}
@@ -0,0 +1,207 @@
+package bonsai
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// The canvas is measured in terminal columns. Most glyphs take one column,
+// East Asian wide characters and emoji take two, and marks that combine
+// with the character before them take none. These tables are a compact
+// approximation of Unicode's East Asian Width and grapheme cluster rules,
+// which is all a bonsai needs.
+
+const (
+	zeroWidthJoiner  = '\u200d'
+	emojiPresent     = '\ufe0f' // Variation selector 16: show as emoji
+	regionalIndicLow = 0x1f1e6
+	regionalIndicHi  = 0x1f1ff
+)
+
+// wideRanges lists the code points shown two columns wide
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f},   // Hangul Jamo initials
+	{0x231a, 0x231b},   // Watch, hourglass
+	{0x23e9, 0x23ec},   // Media controls
+	{0x23f0, 0x23f0},   // Alarm clock
+	{0x23f3, 0x23f3},   // Hourglass
+	{0x25fd, 0x25fe},   // Small squares
+	{0x2614, 0x2615},   // Umbrella, hot beverage
+	{0x2648, 0x2653},   // Zodiac
+	{0x267f, 0x267f},   // Wheelchair
+	{0x2693, 0x2693},   // Anchor
+	{0x26a1, 0x26a1},   // High voltage
+	{0x26aa, 0x26ab},   // Circles
+	{0x26bd, 0x26be},   // Balls
+	{0x26c4, 0x26c5},   // Snowman, sun
+	{0x26ce, 0x26ce},   // Ophiuchus
+	{0x26d4, 0x26d4},   // No entry
+	{0x26ea, 0x26ea},   // Church
+	{0x26f2, 0x26f3},   // Fountain, golf
+	{0x26f5, 0x26f5},   // Sailboat
+	{0x26fa, 0x26fa},   // Tent
+	{0x26fd, 0x26fd},   // Fuel pump
+	{0x2705, 0x2705},   // Check mark
+	{0x270a, 0x270b},   // Fists
+	{0x2728, 0x2728},   // Sparkles
+	{0x274c, 0x274c},   // Cross mark
+	{0x274e, 0x274e},   // Cross mark
+	{0x2753, 0x2755},   // Question marks
+	{0x2757, 0x2757},   // Exclamation mark
+	{0x2795, 0x2797},   // Math signs
+	{0x27b0, 0x27b0},   // Curly loop
+	{0x27bf, 0x27bf},   // Double curly loop
+	{0x2b1b, 0x2b1c},   // Large squares
+	{0x2b50, 0x2b50},   // Star
+	{0x2b55, 0x2b55},   // Circle
+	{0x2e80, 0x303e},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33ff},   // Kana, Bopomofo, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended A
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // Vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms, small forms
+	{0xff00, 0xff60},   // Fullwidth forms
+	{0xffe0, 0xffe6},   // Fullwidth signs
+	{0x16fe0, 0x16fe4}, // Ideographic symbols
+	{0x17000, 0x18cff}, // Tangut, Khitan
+	{0x1b000, 0x1b2ff}, // Kana supplement and extensions
+	{0x1f004, 0x1f004}, // Mahjong tile
+	{0x1f0cf, 0x1f0cf}, // Playing card
+	{0x1f18e, 0x1f18e}, // AB button
+	{0x1f191, 0x1f19a}, // Squared words
+	{0x1f200, 0x1f2ff}, // Enclosed ideographic supplement
+	{0x1f300, 0x1f320}, // Weather, landscapes
+	{0x1f32d, 0x1f335}, // Food, plants
+	{0x1f337, 0x1f37c}, // Plants, food, drink
+	{0x1f37e, 0x1f393}, // Celebration
+	{0x1f3a0, 0x1f3ca}, // Activities
+	{0x1f3cf, 0x1f3d3}, // Sports
+	{0x1f3e0, 0x1f3f0}, // Buildings
+	{0x1f3f4, 0x1f3f4}, // Black flag
+	{0x1f3f8, 0x1f43e}, // Sports, animals
+	{0x1f440, 0x1f440}, // Eyes
+	{0x1f442, 0x1f4fc}, // People, objects
+	{0x1f4ff, 0x1f53d}, // Objects, symbols
+	{0x1f54b, 0x1f54e}, // Religious buildings
+	{0x1f550, 0x1f567}, // Clock faces
+	{0x1f57a, 0x1f57a}, // Dancer
+	{0x1f595, 0x1f596}, // Hands
+	{0x1f5a4, 0x1f5a4}, // Black heart
+	{0x1f5fb, 0x1f64f}, // Landmarks, faces
+	{0x1f680, 0x1f6c5}, // Transport
+	{0x1f6cc, 0x1f6cc}, // Sleeping
+	{0x1f6d0, 0x1f6d2}, // Places of worship, cart
+	{0x1f6d5, 0x1f6d7}, // Buildings
+	{0x1f6dc, 0x1f6df}, // Objects
+	{0x1f6eb, 0x1f6ec}, // Airplanes
+	{0x1f6f4, 0x1f6fc}, // Vehicles
+	{0x1f7e0, 0x1f7eb}, // Colored shapes
+	{0x1f7f0, 0x1f7f0}, // Heavy equals
+	{0x1f90c, 0x1f93a}, // Supplemental symbols and pictographs
+	{0x1f93c, 0x1f945}, // Sports
+	{0x1f947, 0x1f9ff}, // Supplemental symbols and pictographs
+	{0x1fa70, 0x1faff}, // Symbols and pictographs extended A
+	{0x20000, 0x2fffd}, // CJK extension B and later
+	{0x30000, 0x3fffd}, // CJK extension G and later
+}
+
+// runeWidth returns the number of columns r takes up on its own
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x1100:
+		if isCombining(r) {
+			return 0
+		}
+		return 1
+	case isCombining(r) || isExtender(r):
+		return 0
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid].lo:
+			hi = mid
+		case r > wideRanges[mid].hi:
+			lo = mid + 1
+		default:
+			return 2
+		}
+	}
+	return 1
+}
+
+// isCombining reports whether r attaches to the character before it
+func isCombining(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+// isExtender reports whether r continues the cluster before it without
+// being a mark: joiners, variation selectors, skin tones and emoji tags
+func isExtender(r rune) bool {
+	return r == zeroWidthJoiner || r == '\u200c' ||
+		(r >= 0xfe00 && r <= 0xfe0f) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0xe0020 && r <= 0xe007f) ||
+		(r >= 0xe0100 && r <= 0xe01ef)
+}
+
+// isRegionalIndicator reports whether r is half of a flag
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicLow && r <= regionalIndicHi
+}
+
+// graphemes splits s into user-perceived characters: a base character with
+// its combining marks, a joined emoji sequence, or a flag
+func graphemes(s string) []string {
+	var clusters []string
+	start := 0
+	var prev rune = -1
+	flagRunes := 0 // Regional indicators in the current cluster
+	for i, r := range s {
+		joins := prev >= 0 && (isCombining(r) || isExtender(r) || prev == zeroWidthJoiner ||
+			(isRegionalIndicator(r) && flagRunes == 1))
+		if prev >= 0 && !joins {
+			clusters = append(clusters, s[start:i])
+			start = i
+			flagRunes = 0
+		}
+		if isRegionalIndicator(r) {
+			flagRunes++
+		}
+		prev = r
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// clusterWidth returns the number of columns a grapheme cluster takes up
+func clusterWidth(cluster string) int {
+	first, size := utf8.DecodeRuneInString(cluster)
+	if isRegionalIndicator(first) {
+		return 2 // Flags, and lone halves of them
+	}
+	for _, r := range cluster[size:] {
+		if r == emojiPresent {
+			return 2 // Text symbols asking to be shown as emoji, like ☺️
+		}
+	}
+	return runeWidth(first)
+}
+
+// StringWidth returns the number of columns s takes up in a terminal
+func StringWidth(s string) int {
+	width := 0
+	for _, cluster := range graphemes(s) {
+		width += clusterWidth(cluster)
+	}
+	return width
+}
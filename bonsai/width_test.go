@@ -0,0 +1,100 @@
+package bonsai
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"&", 1},
+		{"abc", 3},
+		{"\u00e9", 1},  // Precomposed
+		{"e\u0301", 1}, // e and a combining acute accent
+		{"木", 2},       // CJK
+		{"🌸", 2},       // Emoji
+		{"👍🏽", 2},      // Emoji with a skin tone
+		{"👩‍👩‍👧", 2},   // Joined family
+		{"🇯🇵", 2},      // Flag
+		{"☺", 1},       // Text symbol
+		{"☺️", 2},      // Text symbol shown as emoji
+		{"\t", 0},      // Control characters take up nothing
+		{"木&🌸", 5},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.in); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"ab", []string{"a", "b"}},
+		{"éx", []string{"é", "x"}},
+		{"👩‍👩‍👧!", []string{"👩‍👩‍👧", "!"}},
+		{"🇯🇵🇫🇷", []string{"🇯🇵", "🇫🇷"}},
+		{"👍🏽👍", []string{"👍🏽", "👍"}},
+		{"\u0301a", []string{"\u0301", "a"}}, // A stray mark is a cluster of its own
+	}
+	for _, tt := range tests {
+		if got := graphemes(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("graphemes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWideRangesSorted(t *testing.T) {
+	for i := 1; i < len(wideRanges); i++ {
+		if wideRanges[i].lo <= wideRanges[i-1].hi || wideRanges[i].lo > wideRanges[i].hi {
+			t.Errorf("wide range %d %x-%x is out of order", i, wideRanges[i].lo, wideRanges[i].hi)
+		}
+	}
+}
+
+func TestSetPixelWide(t *testing.T) {
+	tests := []struct {
+		name  string
+		draw  func(bt *Tree)
+		want  string
+		width []int
+	}{
+		{"wide glyph", func(bt *Tree) { bt.SetPixel(1, 0, "木", "") }, " 木  ", []int{1, 2, 0, 1, 1}},
+		{"off the right edge", func(bt *Tree) { bt.SetPixel(4, 0, "木", "") }, "     ", []int{1, 1, 1, 1, 1}},
+		{"string of clusters", func(bt *Tree) { bt.SetPixel(0, 0, "a🌸b", "") }, "a🌸b ", []int{1, 2, 0, 1, 1}},
+		{"left half covered", func(bt *Tree) {
+			bt.SetPixel(1, 0, "木", "")
+			bt.SetPixel(1, 0, "&", "")
+		}, " &   ", []int{1, 1, 1, 1, 1}},
+		{"right half covered", func(bt *Tree) {
+			bt.SetPixel(1, 0, "木", "")
+			bt.SetPixel(2, 0, "&", "")
+		}, "  &  ", []int{1, 1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Width, config.Height = 5, 1
+			bt := NewTree(config)
+			tt.draw(bt)
+			var widths []int
+			for _, cell := range bt.canvas[0] {
+				widths = append(widths, cell.Width)
+			}
+			if got := bt.Snapshot().String(); got != tt.want+"\n" {
+				t.Errorf("canvas is %q, want %q", got, tt.want)
+			}
+			if !slices.Equal(widths, tt.width) {
+				t.Errorf("cell widths %v, want %v", widths, tt.width)
+			}
+		})
+	}
+}
@@ -1,119 +1,102 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
-	"math/rand"
+	"image/color"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"gobonsai/v2/bonsai"
 	"golang.org/x/term"
 )
 
-// BranchType represents different types of branches
-type BranchType int
+// screen is where all terminal drawing goes, so it can be recorded
+var screen io.Writer = os.Stdout
 
+// Size of the canvas when stdout isn't a terminal
 const (
-	Trunk BranchType = iota
-	ShootLeft
-	ShootRight
-	Dying
-	Dead
+	defaultWidth  = 80
+	defaultHeight = 24
 )
 
-// Config holds all configuration options
-type Config struct {
-	Live       bool
-	Infinite   bool
-	PrintTree  bool
-	LifeStart  int
-	Multiplier int
-	BaseType   int
-	Seed       int64
-	TimeStep   float64
-	TimeWait   float64
-	Message    string
-	Leaves     []string
-	Width      int
-	Height     int
-	UseColors  bool
+// getTerminalSize returns the size of the terminal, and whether there is
+// one. Without a terminal it returns the default size.
+func getTerminalSize() (int, int, bool) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight, false
+	}
+	return width, height, true
 }
 
-// Color constants for ANSI escape codes
-const (
-	ColorReset = "\033[0m"
-	ColorBold  = "\033[1m"
-
-	// Text colors
-	ColorBlack   = "\033[30m"
-	ColorRed     = "\033[31m"
-	ColorGreen   = "\033[32m"
-	ColorYellow  = "\033[33m"
-	ColorBlue    = "\033[34m"
-	ColorMagenta = "\033[35m"
-	ColorCyan    = "\033[36m"
-	ColorWhite   = "\033[37m"
-
-	// Bright colors
-	ColorBrightBlack   = "\033[90m"
-	ColorBrightRed     = "\033[91m"
-	ColorBrightGreen   = "\033[92m"
-	ColorBrightYellow  = "\033[93m"
-	ColorBrightBlue    = "\033[94m"
-	ColorBrightMagenta = "\033[95m"
-	ColorBrightCyan    = "\033[96m"
-	ColorBrightWhite   = "\033[97m"
-
-	// 256-color support
-	ColorBrown       = "\033[38;5;94m"  // Brown for branches
-	ColorDarkBrown   = "\033[38;5;52m"  // Dark brown for trunk
-	ColorLightBrown  = "\033[38;5;130m" // Light brown for branches
-	ColorDarkGreen   = "\033[38;5;22m"  // Dark green for leaves
-	ColorMediumGreen = "\033[38;5;28m"  // Medium green for leaves
-	ColorTerracotta  = "\033[38;5;166m" // Terracotta for pot
-	ColorOrange      = "\033[38;5;214m" // Orange/autumn leaves
-)
+// queryBackground asks the terminal for its background color with OSC 11,
+// giving up after timeout. The terminal is in raw mode meanwhile, so the
+// reply is neither echoed nor left for the shell to read.
+func queryBackground(timeout time.Duration) (color.RGBA, bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	defer tty.Close()
 
-// Point represents a coordinate
-type Point struct {
-	X, Y int
-}
+	// Fd would switch the file to blocking mode, which ignores deadlines
+	fd := -1
+	if conn, err := tty.SyscallConn(); err == nil {
+		conn.Control(func(f uintptr) { fd = int(f) })
+	}
+	if fd < 0 || tty.SetReadDeadline(time.Now().Add(timeout)) != nil {
+		return color.RGBA{}, false
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	defer term.Restore(fd, state)
 
-// BonsaiTree represents the tree structure
-type BonsaiTree struct {
-	canvas        [][]rune
-	colorCanvas   [][]string // Store color for each character
-	config        *Config
-	branches      int
-	shoots        int
-	rng           *rand.Rand
-	initialized   bool
-	messageOffset int
-}
+	if _, err := tty.WriteString(bonsai.BackgroundQuery); err != nil {
+		return color.RGBA{}, false
+	}
 
-// Terminal size detection
-func getTerminalSize() (int, int) {
-	width, height, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		fmt.Printf("Error getting terminal size: %v\n", err)
-		return 80, 24 // Default fallback
+	// Read until the device attributes reply, which comes last
+	var reply []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := tty.Read(buf)
+		reply = append(reply, buf[:n]...)
+		if err != nil {
+			break
+		}
+		if i := bytes.LastIndex(reply, []byte("\033[?")); i >= 0 && bytes.IndexByte(reply[i:], 'c') >= 0 {
+			break
+		}
 	}
-	return width, height
+	return bonsai.ParseBackgroundReply(string(reply))
 }
 
 func saveConsole() {
-	fmt.Print("\033[s")    // Save cursor position
-	fmt.Print("\033[?47h") // Switch to alternate screen buffer
+	fmt.Fprint(screen, "\033[s")    // Save cursor position
+	fmt.Fprint(screen, "\033[?47h") // Switch to alternate screen buffer
 }
 
 func restoreConsole() {
-	fmt.Print("\033[?47l") // Switch back to normal screen buffer
-	fmt.Print("\033[u")    // Restore cursor position
+	fmt.Fprint(screen, "\033[?47l") // Switch back to normal screen buffer
+	fmt.Fprint(screen, "\033[u")    // Restore cursor position
+}
+
+// clearScreen clears the alternate screen buffer and moves the cursor to top-left
+func clearScreen() {
+	fmt.Fprint(screen, "\033[2J") // Clear entire screen
+	fmt.Fprint(screen, "\033[H")  // Move cursor to top-left
 }
 
 // setupSignalHandler sets up a signal handler to restore console on interrupt
@@ -123,649 +106,270 @@ func setupSignalHandler() {
 	go func() {
 		<-c
 		restoreConsole()
-		fmt.Print("\033[?25h") // Show cursor
+		fmt.Fprint(screen, "\033[?25h") // Show cursor
 		os.Exit(0)
 	}()
 }
 
-// NewBonsaiTree creates a new bonsai tree
-func NewBonsaiTree(config *Config) *BonsaiTree {
-	width, height := getTerminalSize()
-	config.Width = width
-	if config.PrintTree {
-		config.Height = height - 1
-	} else {
-		config.Height = height
-	}
-
-	canvas := make([][]rune, height)
-	colorCanvas := make([][]string, height)
-	for i := range canvas {
-		canvas[i] = make([]rune, width)
-		colorCanvas[i] = make([]string, width)
-		for j := range canvas[i] {
-			canvas[i][j] = ' '
-			colorCanvas[i][j] = ""
-		}
-	}
-
-	return &BonsaiTree{
-		canvas:        canvas,
-		colorCanvas:   colorCanvas,
-		config:        config,
-		rng:           rand.New(rand.NewSource(config.Seed)),
-		initialized:   false,
-		messageOffset: 0,
-	}
+// Options holds the CLI-only settings that are not part of bonsai.Config
+type Options struct {
+	Infinite  bool
+	PrintTree bool
+	TimeWait  float64
+	Output    string  // File to write the finished tree to, "-" for stdout
+	Format    string  // Output format, guessed from Output when empty
+	CellSize  string  // Image cell size as WxH pixels
+	BgColor   string  // Image background as a hex color
+	Padding   int     // Image padding in pixels
+	Every     int     // GIF: record every Nth growth step
+	Hold      float64 // GIF: seconds to hold the final frame
+	Record    string  // asciicast file to record terminal output to
+	Animate   bool    // HTML: reveal cells in the order they were drawn
+	Save      string  // File to save each grown tree to
+	Load      string  // Saved tree to grow again
 }
 
-// GetBranchColor returns the appropriate color for branch types
-func (bt *BonsaiTree) GetBranchColor(branchType BranchType) string {
-	if !bt.config.UseColors {
-		return ""
+// saveTree writes the config of a grown tree to path
+func saveTree(path string, config *bonsai.Config) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-
-	switch branchType {
-	case Trunk:
-		return ColorYellow
-	case ShootLeft, ShootRight:
-		// Lighter browns for smaller branches
-		if bt.rng.Intn(4) == 0 {
-			return ColorYellow
-		}
-		return ColorBrightYellow
-	case Dying, Dead:
-		// Green leaves with occasional brown/yellow
-		dice := bt.rng.Intn(10)
-		switch {
-		case dice <= 8:
-			return ColorBrightGreen // Some darker green
-		case dice == 9:
-			return ColorMediumGreen // Some yellow/autumn leaves
-		case dice == 9:
-			return ColorBrightYellow // Some brown/dead leaves
-		}
+	if err := bonsai.SaveConfig(f, config); err != nil {
+		f.Close()
+		return err
 	}
-	return ""
+	return f.Close()
 }
 
-// GetBaseColor returns the appropriate color for the pot/base
-func (bt *BonsaiTree) GetBaseColor() string {
-	if !bt.config.UseColors {
-		return ""
+// loadTree reads a saved tree config from path
+func loadTree(path string) (*bonsai.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
-	return ColorBrightBlack
-}
-
-// MoveCursor moves cursor to specific position (1-based coordinates)
-func (bt *BonsaiTree) MoveCursor(x, y int) {
-	fmt.Printf("\033[%d;%dH", y, x)
+	defer f.Close()
+	return bonsai.LoadConfig(f)
 }
 
-// ClearScreen clears the screen using the alternate buffer (preserves original console)
-func (bt *BonsaiTree) ClearScreen() {
-	// Only clear screen for interactive modes, not for print mode
-	if !bt.config.PrintTree {
-		// Clear the alternate screen buffer
-		fmt.Print("\033[2J") // Clear entire screen
-		fmt.Print("\033[H")  // Move cursor to top-left
+// glyphTheme finds a built-in glyph theme by name, or loads one from a file
+func glyphTheme(name string) (bonsai.GlyphTheme, error) {
+	if theme, ok := bonsai.LookupGlyphTheme(name); ok {
+		return theme, nil
 	}
-}
-
-// SetPixelLive sets a character at the given position and immediately renders it in live mode
-func (bt *BonsaiTree) SetPixelLive(x, y int, char rune, color string) {
-	if y >= 0 && y < len(bt.canvas) && x >= 0 && x < len(bt.canvas[y]) {
-		bt.canvas[y][x] = char
-		if bt.config.Live {
-			bt.MoveCursor(x+1, y+1) // Convert to 1-based coordinates
-			if color != "" {
-				fmt.Printf("%s%c%s", color, char, ColorReset)
-			} else {
-				fmt.Printf("%c", char)
-			}
-			os.Stdout.Sync() // Ensure immediate output
-		}
+	f, err := os.Open(name)
+	if err != nil {
+		return bonsai.GlyphTheme{}, fmt.Errorf("no glyph theme %q, the built-in ones are %s", name, strings.Join(bonsai.GlyphThemes(), ", "))
 	}
+	defer f.Close()
+	return bonsai.LoadGlyphTheme(f)
 }
 
-// SetPixel sets a character at the given position
-func (bt *BonsaiTree) SetPixel(x, y int, char rune, color string) {
-	if y >= 0 && y < len(bt.canvas) && x >= 0 && x < len(bt.canvas[y]) {
-		bt.canvas[y][x] = char
-		bt.colorCanvas[y][x] = color
+// palette finds a built-in color theme by name, or loads one from a file
+func palette(name string) (bonsai.Palette, error) {
+	if p, ok := bonsai.LookupPalette(name); ok {
+		return p, nil
 	}
-}
-
-// GetDeltas calculates movement deltas based on branch type and age
-func (bt *BonsaiTree) GetDeltas(branchType BranchType, life, age int) (int, int) {
-	dx, dy := 0, 0
-
-	switch branchType {
-	case Trunk:
-		if age <= 2 || life < 4 {
-			dy = 0
-			dx = bt.rng.Intn(3) - 1
-		} else if age < (bt.config.Multiplier * 3) {
-			if age%(int(float64(bt.config.Multiplier)*0.5+0.5)) == 0 {
-				dy = -1
-			} else {
-				dy = 0
-			}
-			dice := bt.rng.Intn(10)
-			switch {
-			case dice == 0:
-				dx = -2
-			case dice <= 3:
-				dx = -1
-			case dice <= 5:
-				dx = 0
-			case dice <= 8:
-				dx = 1
-			case dice == 9:
-				dx = 2
-			}
-		} else {
-			if bt.rng.Intn(10) > 2 {
-				dy = -1
-			} else {
-				dy = 0
-			}
-			dx = bt.rng.Intn(3) - 1
-		}
-
-	case ShootLeft:
-		dice := bt.rng.Intn(10)
-		switch {
-		case dice <= 1:
-			dy = -1
-		case dice <= 7:
-			dy = 0
-		default:
-			dy = 1
-		}
-		dice = bt.rng.Intn(10)
-		switch {
-		case dice <= 1:
-			dx = -2
-		case dice <= 5:
-			dx = -1
-		case dice <= 8:
-			dx = 0
-		default:
-			dx = 1
-		}
-
-	case ShootRight:
-		dice := bt.rng.Intn(10)
-		switch {
-		case dice <= 1:
-			dy = -1
-		case dice <= 7:
-			dy = 0
-		default:
-			dy = 1
-		}
-		dice = bt.rng.Intn(10)
-		switch {
-		case dice <= 1:
-			dx = 2
-		case dice <= 5:
-			dx = 1
-		case dice <= 8:
-			dx = 0
-		default:
-			dx = -1
-		}
-
-	case Dying:
-		dice := bt.rng.Intn(10)
-		switch {
-		case dice <= 1:
-			dy = -1
-		case dice <= 8:
-			dy = 0
-		default:
-			dy = 1
-		}
-		dice = bt.rng.Intn(15)
-		switch {
-		case dice == 0:
-			dx = -3
-		case dice <= 2:
-			dx = -2
-		case dice <= 5:
-			dx = -1
-		case dice <= 8:
-			dx = 0
-		case dice <= 11:
-			dx = 1
-		case dice <= 13:
-			dx = 2
-		default:
-			dx = 3
-		}
-
-	case Dead:
-		dice := bt.rng.Intn(10)
-		switch {
-		case dice <= 2:
-			dy = -1
-		case dice <= 6:
-			dy = 0
-		default:
-			dy = 1
-		}
-		dx = bt.rng.Intn(3) - 1
+	f, err := os.Open(name)
+	if err != nil {
+		return bonsai.Palette{}, fmt.Errorf("no theme %q, the built-in ones are %s", name, strings.Join(bonsai.Palettes(), ", "))
 	}
-
-	return dx, dy
+	defer f.Close()
+	return bonsai.LoadPalette(f)
 }
 
-// ChooseChar selects the appropriate character for the branch
-func (bt *BonsaiTree) ChooseChar(branchType BranchType, life, dx, dy int) rune {
-	if life < 4 {
-		branchType = Dying
-	}
-
-	switch branchType {
-	case Trunk:
-		if dy == 0 {
-			return '~'
-		} else if dx < 0 {
-			return '\\'
-		} else if dx == 0 {
-			return '|'
-		} else {
-			return '/'
-		}
-
-	case ShootLeft:
-		if dy > 0 {
-			return '\\'
-		} else if dy == 0 {
-			return '_'
-		} else if dx < 0 {
-			return '\\'
-		} else if dx == 0 {
-			return '|'
-		} else {
-			return '/'
-		}
-
-	case ShootRight:
-		if dy > 0 {
-			return '/'
-		} else if dy == 0 {
-			return '_'
-		} else if dx < 0 {
-			return '\\'
-		} else if dx == 0 {
-			return '|'
-		} else {
-			return '/'
-		}
-
-	case Dying, Dead:
-		if len(bt.config.Leaves) > 0 {
-			return rune(bt.config.Leaves[bt.rng.Intn(len(bt.config.Leaves))][0])
+// imageOptions builds the raster options from the image flags
+func imageOptions(opts *Options) (bonsai.ImageOptions, error) {
+	img := bonsai.ImageOptions{Padding: opts.Padding}
+	if opts.CellSize != "" {
+		if _, err := fmt.Sscanf(opts.CellSize, "%dx%d", &img.CellWidth, &img.CellHeight); err != nil {
+			return img, fmt.Errorf("invalid cell size: %s", opts.CellSize)
 		}
-		return '&'
 	}
-
-	return '?'
-}
-
-// Branch generates a branch recursively
-func (bt *BonsaiTree) Branch(x, y int, branchType BranchType, life int) {
-	bt.branches++
-	shootCooldown := bt.config.Multiplier
-
-	for life > 0 {
-		life--
-		age := bt.config.LifeStart - life
-
-		dx, dy := bt.GetDeltas(branchType, life, age)
-
-		// Prevent going too close to ground
-		if dy > 0 && y > (bt.config.Height-7) {
-			dy--
-		}
-		// Ensure first move is at least 1 up
-		if age == 1 && dy >= 0 {
-			dy = -2
-		}
-
-		// Branching logic
-		if life < 3 {
-			bt.Branch(x, y, Dead, life)
-		} else if branchType == Trunk && life < (bt.config.Multiplier+2) {
-			bt.Branch(x, y, Dying, life)
-		} else if (branchType == ShootLeft || branchType == ShootRight) && life < (bt.config.Multiplier+2) {
-			bt.Branch(x, y, Dying, life)
-		} else if branchType == Trunk && (bt.rng.Intn(3) == 0 || life%bt.config.Multiplier == 0) {
-			if bt.rng.Intn(8) == 0 && life > 7 {
-				shootCooldown = bt.config.Multiplier * 2
-				bt.Branch(x, y, Trunk, life+bt.rng.Intn(5)-2)
-			} else if shootCooldown <= 0 {
-				shootCooldown = bt.config.Multiplier * 2
-				shootLife := life + bt.config.Multiplier
-				bt.shoots++
-				if bt.shoots%2 == 0 {
-					bt.Branch(x, y, ShootLeft, shootLife)
-				} else {
-					bt.Branch(x, y, ShootRight, shootLife)
-				}
-			}
-		}
-		shootCooldown--
-
-		// Move and draw
-		x += dx
-		y += dy
-
-		char := bt.ChooseChar(branchType, life, dx, dy)
-		color := bt.GetBranchColor(branchType)
-		if bt.config.Live {
-			bt.SetPixelLive(x, y, char, color)
-		} else {
-			bt.SetPixel(x, y, char, color)
-		}
-
-		// Live mode animation
-		if bt.config.Live {
-			time.Sleep(time.Duration(bt.config.TimeStep * float64(time.Second)))
+	if opts.BgColor != "" {
+		bg, err := bonsai.ParseHexColor(opts.BgColor)
+		if err != nil {
+			return img, err
 		}
+		img.Background = bg
 	}
+	return img, nil
 }
 
-// DrawBase draws the base of the tree
-func (bt *BonsaiTree) DrawBase() {
-	if bt.config.BaseType == 0 {
-		return
+// backgroundColor returns --bg-color as a #rrggbb color for the vector
+// formats, or "" when it isn't set
+func backgroundColor(opts *Options) (string, error) {
+	if opts.BgColor == "" {
+		return "", nil
 	}
-
-	baseY := bt.config.Height - 1
-	centerX := bt.config.Width / 2
-	grassColor := ""
-	if bt.config.UseColors {
-		grassColor = ColorBrightGreen
-	}
-
-	switch bt.config.BaseType {
-	case 1:
-		line3 := ":___________./~~~\\.___________:"
-		startX := centerX - len(line3)/2
-		baseColor := bt.GetBaseColor()
-
-		// Draw the pot markers (^) in gray/base color and grass in between
-		for i, char := range line3 {
-			var currentColor string
-			if char == '(' || char == ')' || char == '^' {
-				// Pot markers in gray/base color
-				currentColor = baseColor
-			} else if char == '_' {
-				// Replace '_' with a grass character and make it green
-				grassChars := ".,~`'^\"*o%"
-				index := ((i - 7) + len(grassChars)) % len(grassChars)
-				char = rune(grassChars[index])
-				currentColor = grassColor
-			} else if char == '.' || char == '/' || char == '~' || char == '\\' {
-				// Turn ., /, ~ brown
-				currentColor = ColorYellow
-			} else if i > 0 && i < 30 { // Between the (^) markers
-				// Grass in the middle section
-				if char == ' ' {
-					// Replace spaces with grass characters
-					grassChars := ".,~`'^\"*o%"
-					char = rune(grassChars[(i-7)%len(grassChars)])
-				}
-				currentColor = grassColor
-			} else {
-				// Spaces outside - keep as base color
-				currentColor = baseColor
-			}
-
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY-3, char, currentColor)
-			} else {
-				bt.SetPixel(startX+i, baseY-3, char, currentColor)
-			}
-		}
-
-		line2 := " \\                           / "
-		startX = centerX - len(line2)/2
-		for i, char := range line2 {
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY-2, char, baseColor)
-			} else {
-				bt.SetPixel(startX+i, baseY-2, char, baseColor)
-			}
-		}
-
-		line1 := "  \\_________________________/ "
-		startX = centerX - len(line1)/2
-		for i, char := range line1 {
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY-1, char, baseColor)
-			} else {
-				bt.SetPixel(startX+i, baseY-1, char, baseColor)
-			}
-		}
-
-		base := "   (^)                 (^)   "
-		startX = centerX - len(base)/2
-		for i, char := range base {
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY, char, baseColor)
-			} else {
-				bt.SetPixel(startX+i, baseY, char, baseColor)
-			}
-		}
-
-	case 2:
-		line3 := ":_______./~~~\\._______:"
-		startX := centerX - len(line3)/2
-		baseColor := bt.GetBaseColor()
-
-		// Draw the pot markers (^) in gray/base color and grass in between
-		for i, char := range line3 {
-			var currentColor string
-			if char == '(' || char == ')' || char == '^' {
-				// Pot markers in gray/base color
-				currentColor = baseColor
-			} else if char == '_' {
-				// Replace '_' with a grass character and make it green
-				grassChars := ".,~`'^\"*o%"
-				index := ((i - 7) + len(grassChars)) % len(grassChars)
-				char = rune(grassChars[index])
-				currentColor = grassColor
-			} else if char == '.' || char == '/' || char == '~' || char == '\\' {
-				// Turn ., /, ~ brown
-				currentColor = ColorYellow
-			} else if i > 0 && i < 30 { // Between the (^) markers
-				// Grass in the middle section
-				if char == ' ' {
-					// Replace spaces with grass characters
-					grassChars := ".,~`'^\"*o%"
-					char = rune(grassChars[(i-7)%len(grassChars)])
-				}
-				currentColor = grassColor
-			} else {
-				// Spaces outside - keep as base color
-				currentColor = baseColor
-			}
-
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY-3, char, currentColor)
-			} else {
-				bt.SetPixel(startX+i, baseY-3, char, currentColor)
-			}
-		}
-
-		line2 := " \\                   / "
-		startX = centerX - len(line2)/2
-		for i, char := range line2 {
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY-2, char, baseColor)
-			} else {
-				bt.SetPixel(startX+i, baseY-2, char, baseColor)
-			}
-		}
-
-		line1 := "  \\_________________/ "
-		startX = centerX - len(line1)/2
-		for i, char := range line1 {
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY-1, char, baseColor)
-			} else {
-				bt.SetPixel(startX+i, baseY-1, char, baseColor)
-			}
-		}
-
-		base := "   (^)          (^)   "
-		startX = centerX - len(base)/2
-		for i, char := range base {
-			if bt.config.Live {
-				bt.SetPixelLive(startX+i, baseY, char, baseColor)
-			} else {
-				bt.SetPixel(startX+i, baseY, char, baseColor)
-			}
-		}
-
+	bg, err := bonsai.ParseHexColor(opts.BgColor)
+	if err != nil {
+		return "", err
 	}
+	return bonsai.HexColor(bg), nil
 }
 
-// Render displays the current state of the tree
-func (bt *BonsaiTree) Render() {
-	if !bt.initialized {
-		bt.ClearScreen()
-		bt.initialized = true
-	}
-
-	// Only render the full screen if not in live mode
-	if !bt.config.Live {
-		// For print mode, don't use cursor positioning
-		if bt.config.PrintTree {
-			for y := 0; y < len(bt.canvas); y++ {
-				for x := 0; x < len(bt.canvas[y]); x++ {
-					char := bt.canvas[y][x]
-					color := bt.colorCanvas[y][x]
-					if color != "" && bt.config.UseColors {
-						fmt.Printf("%s%c%s", color, char, ColorReset)
-					} else {
-						fmt.Printf("%c", char)
-					}
-				}
-				fmt.Println()
-			}
-			if bt.config.Message != "" {
-				fmt.Printf("\n%s\n", bt.config.Message)
-			}
-		} else {
-			// For interactive mode, use cursor positioning
-			bt.MoveCursor(1, 1)
-			for y := 0; y < len(bt.canvas); y++ {
-				for x := 0; x < len(bt.canvas[y]); x++ {
-					char := bt.canvas[y][x]
-					color := bt.colorCanvas[y][x]
-					if color != "" && bt.config.UseColors {
-						fmt.Printf("%s%c%s", color, char, ColorReset)
-					} else {
-						fmt.Printf("%c", char)
-					}
-				}
-				fmt.Println()
-			}
-			if bt.config.Message != "" {
-				fmt.Printf("\n%s\n", bt.config.Message)
-			}
-		}
-	} else {
-		// In live mode, render message if it hasn't been rendered yet
-		if bt.config.Message != "" && bt.messageOffset == 0 {
-			bt.MoveCursor(1, bt.config.Height+2)
-			fmt.Printf("%s", bt.config.Message)
-			bt.messageOffset = len(bt.config.Message)
-		}
-	}
+// outputFormat picks the export format from --format or the --output extension
+func outputFormat(opts *Options) string {
+	if opts.Format != "" {
+		return strings.ToLower(opts.Format)
+	}
+	switch strings.ToLower(filepath.Ext(opts.Output)) {
+	case ".svg":
+		return "svg"
+	case ".png":
+		return "png"
+	case ".gif":
+		return "gif"
+	case ".html", ".htm":
+		return "html"
+	case ".json":
+		return "json"
+	}
+	return "text"
 }
 
-// GrowTree generates the complete tree
-func (bt *BonsaiTree) GrowTree() {
-	bt.branches = 0
-	bt.shoots = 0
-	bt.initialized = false
-	bt.messageOffset = 0
-
-	// Clear canvas
-	for i := range bt.canvas {
-		for j := range bt.canvas[i] {
-			bt.canvas[i][j] = ' '
-			bt.colorCanvas[i][j] = ""
-		}
+// exportTree grows a tree without drawing it live and writes it to --output.
+// Text written to stdout has its colors fitted to depth.
+func exportTree(ctx context.Context, config *bonsai.Config, opts *Options, depth bonsai.ColorDepth) error {
+	config.Live = false
+	config.RecordScene = outputFormat(opts) == "json"
+	tree := bonsai.NewTree(config)
+	img, err := imageOptions(opts)
+	if err != nil {
+		return err
 	}
-
-	// Initialize screen for live mode
-	if bt.config.Live {
-		bt.ClearScreen()
-		bt.initialized = true
+	background, err := backgroundColor(opts)
+	if err != nil {
+		return err
+	}
+
+	// Animated formats record the growth, the rest only need the result
+	var gifRec *bonsai.GIFRecorder
+	var htmlRec *bonsai.HTMLRecorder
+	var rec bonsai.Renderer
+	htmlOpts := bonsai.HTMLOptions{Background: background, TimeStep: config.TimeStep}
+	switch outputFormat(opts) {
+	case "gif":
+		width, height := tree.Size()
+		gifRec = bonsai.NewGIFRecorder(bonsai.GIFOptions{
+			ImageOptions: img,
+			Every:        opts.Every,
+			TimeStep:     config.TimeStep,
+			Hold:         opts.Hold,
+			Width:        width,
+			Height:       height,
+		})
+		rec = gifRec
+	case "html":
+		if opts.Animate {
+			htmlRec = bonsai.NewHTMLRecorder(htmlOpts)
+			rec = htmlRec
+		}
+	}
+	if err := tree.GrowWith(ctx, rec); err != nil {
+		return err
+	}
+	if opts.Save != "" {
+		if err := saveTree(opts.Save, config); err != nil {
+			return err
+		}
+	}
+
+	out := os.Stdout
+	if opts.Output != "" && opts.Output != "-" {
+		f, err := os.Create(opts.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch format := outputFormat(opts); format {
+	case "text":
+		var r bonsai.Renderer = bonsai.NewTextRenderer(out, config.UseColors && out == os.Stdout)
+		if out == os.Stdout {
+			r = bonsai.Downsample(r, depth)
+		}
+		err = tree.RenderWith(ctx, r)
+	case "svg":
+		err = bonsai.WriteSVG(out, tree.Snapshot(), bonsai.SVGOptions{Background: background})
+	case "png":
+		err = bonsai.WritePNG(out, tree.Snapshot(), img)
+	case "gif":
+		err = gifRec.Encode(out)
+	case "json":
+		err = tree.WriteJSON(out)
+	case "html":
+		if htmlRec != nil {
+			err = htmlRec.Encode(out)
+		} else {
+			err = bonsai.WriteHTML(out, tree.Snapshot(), htmlOpts)
+		}
+	default:
+		err = fmt.Errorf("unknown format: %s", format)
 	}
-
-	bt.DrawBase()
-
-	startX := bt.config.Width / 2
-	startY := bt.config.Height + 2
-	if bt.config.BaseType > 0 {
-		startY -= 5 // Account for base height + grass line above the pot
+	if err != nil {
+		return err
 	}
-
-	bt.Branch(startX, startY, Trunk, bt.config.LifeStart)
-
-	if !bt.config.Live {
-		bt.Render()
+	if out != os.Stdout {
+		return out.Close()
 	}
+	return nil
 }
 
 func main() {
-	config := &Config{
-		Live:       false,
-		Infinite:   false,
-		PrintTree:  false,
-		LifeStart:  32,
-		Multiplier: 5,
-		BaseType:   1,
-		Seed:       0,
-		TimeStep:   0.03,
-		TimeWait:   4.0,
-		Message:    "",
-		Leaves:     []string{"&", "*", "o", "@", "%"},
-		UseColors:  true, // Enable colors by default
+	config := bonsai.DefaultConfig()
+	opts := &Options{
+		Infinite:  false,
+		PrintTree: false,
+		TimeWait:  4.0,
 	}
 
 	// Parse command line flags
 	flag.BoolVar(&config.Live, "live", false, "Live mode: show each step of growth")
 	flag.BoolVar(&config.Live, "l", false, "Live mode: show each step of growth")
-	flag.BoolVar(&config.Infinite, "infinite", false, "Infinite mode: keep growing trees")
-	flag.BoolVar(&config.Infinite, "i", false, "Infinite mode: keep growing trees")
-	flag.BoolVar(&config.PrintTree, "print", false, "Print tree to terminal when finished")
-	flag.BoolVar(&config.PrintTree, "p", false, "Print tree to terminal when finished")
+	flag.BoolVar(&opts.Infinite, "infinite", false, "Infinite mode: keep growing trees")
+	flag.BoolVar(&opts.Infinite, "i", false, "Infinite mode: keep growing trees")
+	flag.BoolVar(&opts.PrintTree, "print", false, "Print tree to terminal when finished")
+	flag.BoolVar(&opts.PrintTree, "p", false, "Print tree to terminal when finished")
 	flag.IntVar(&config.LifeStart, "life", 32, "Life: higher -> more growth (0-200)")
 	flag.IntVar(&config.LifeStart, "L", 32, "Life: higher -> more growth (0-200)")
-	flag.IntVar(&config.Multiplier, "multiplier", 5, "Branch multiplier: higher -> more branching (0-20)")
-	flag.IntVar(&config.Multiplier, "M", 5, "Branch multiplier: higher -> more branching (0-20)")
+	flag.IntVar(&config.Multiplier, "multiplier", 5, "Branch multiplier: higher -> more branching (1-20)")
+	flag.IntVar(&config.Multiplier, "M", 5, "Branch multiplier: higher -> more branching (1-20)")
 	flag.IntVar(&config.BaseType, "base", 1, "ASCII-art plant base to use, 0 is none")
 	flag.IntVar(&config.BaseType, "b", 1, "ASCII-art plant base to use, 0 is none")
 	flag.Float64Var(&config.TimeStep, "time", 0.03, "In live mode, wait TIME secs between steps")
 	flag.Float64Var(&config.TimeStep, "t", 0.03, "In live mode, wait TIME secs between steps")
-	flag.Float64Var(&config.TimeWait, "wait", 4.0, "In infinite mode, wait TIME between each tree")
-	flag.Float64Var(&config.TimeWait, "w", 4.0, "In infinite mode, wait TIME between each tree")
+	flag.Float64Var(&config.FPS, "fps", 0, "In live mode, paint N frames per second however fast the tree grows (default a frame per step)")
+	flag.Float64Var(&config.Duration, "duration", 0, "In live mode, grow the whole tree in TIME secs, overriding --time")
+	var width, height int
+	flag.IntVar(&width, "width", 0, "Grow the tree on a canvas N cells wide, placed in the terminal (default the terminal width, or 80)")
+	flag.IntVar(&height, "height", 0, "Grow the tree on a canvas N cells high, placed in the terminal (default the terminal height, or 24)")
+	flag.Float64Var(&opts.TimeWait, "wait", 4.0, "In infinite mode, wait TIME between each tree")
+	flag.Float64Var(&opts.TimeWait, "w", 4.0, "In infinite mode, wait TIME between each tree")
 	flag.StringVar(&config.Message, "message", "", "Attach message next to the tree")
 	flag.StringVar(&config.Message, "m", "", "Attach message next to the tree")
+
+	var messagePos string
+	flag.StringVar(&messagePos, "message-pos", "right", "Where to put the message: right, left or below")
 	flag.BoolVar(&config.UseColors, "color", true, "Use colors (green leaves, brown branches, colored pot)")
 	flag.BoolVar(&config.UseColors, "C", true, "Use colors (green leaves, brown branches, colored pot)")
 
+	var style string
+	var braille bool
+	flag.StringVar(&style, "style", "text", "How to draw the tree: text, braille (finer detail) or pixels (half-block pixel art)")
+	flag.BoolVar(&braille, "braille", false, "Same as --style braille")
+	var leafLayer string
+	flag.StringVar(&leafLayer, "leaf-layer", "", "Draw leaves in front of or behind branches: front or behind (default whatever grows last)")
+
 	var noColor bool
 	flag.BoolVar(&noColor, "no-color", false, "Disable colors")
+	var backgroundStr string
+	flag.StringVar(&backgroundStr, "background", "auto", "Terminal background to pick colors for: light, dark, or auto to ask the terminal")
+	var depthStr string
+	flag.StringVar(&depthStr, "color-depth", "auto", "Colors the terminal can show: none, 16, 256, truecolor, or auto to detect them")
 
 	var seedStr string
 	var leavesStr string
@@ -774,6 +378,37 @@ func main() {
 	flag.StringVar(&leavesStr, "leaf", "&,*,o,@,%", "List of comma-delimited strings for leaves")
 	flag.StringVar(&leavesStr, "c", "&,*,o,@,%", "List of comma-delimited strings for leaves")
 
+	var algorithmStr string
+	flag.StringVar(&algorithmStr, "algorithm", string(bonsai.LatestAlgorithm), "Growth algorithm: "+strings.Join(bonsai.Algorithms(), ", ")+", so a seed keeps growing the same tree")
+
+	var glyphsStr string
+	flag.StringVar(&glyphsStr, "glyphs", "ascii", "Glyph theme: "+strings.Join(bonsai.GlyphThemes(), ", ")+", or a JSON theme FILE")
+
+	var themeStr string
+	flag.StringVar(&themeStr, "theme", "classic", "Color theme: "+strings.Join(bonsai.Palettes(), ", ")+", or a JSON theme FILE")
+	var shadingStr string
+	flag.StringVar(&shadingStr, "shading", "random", "How to pick theme colors: random, or gradient to shade by age and height")
+
+	var dyingStr, deadStr, trunkStr, shootStr string
+	flag.StringVar(&dyingStr, "dying-leaf", "", "Leaves for dying branches, like --leaf (default --leaf)")
+	flag.StringVar(&deadStr, "dead-leaf", "", "Leaves for dead branches, like --leaf (default --leaf)")
+	flag.StringVar(&trunkStr, "trunk", "", "Trunk glyphs by direction, e.g. \"up=|:3,‖ flat=~\"")
+	flag.StringVar(&shootStr, "shoot", "", "Shoot glyphs by direction, e.g. \"flat=- up=|:2,!\"")
+
+	flag.StringVar(&opts.Output, "output", "", "Write the finished tree to FILE (- for stdout) instead of drawing it")
+	flag.StringVar(&opts.Output, "o", "", "Write the finished tree to FILE (- for stdout) instead of drawing it")
+	flag.StringVar(&opts.Format, "format", "", "Output format: text, svg, png, gif, html or json (default from --output extension)")
+	flag.StringVar(&opts.CellSize, "cell-size", "", "Image cell size in pixels as WxH (default 7x13)")
+	flag.StringVar(&opts.BgColor, "bg-color", "", "Image background color, e.g. #1e1e2e (default black, SVG transparent)")
+	flag.IntVar(&opts.Padding, "padding", 0, "Image padding in pixels")
+	flag.IntVar(&opts.Every, "every", 1, "In GIF output, record every Nth growth step")
+	flag.Float64Var(&opts.Hold, "hold", 0, "In GIF output, hold the finished tree for TIME secs")
+	flag.BoolVar(&opts.Animate, "animate", false, "In HTML output, animate the growth with CSS")
+	flag.StringVar(&opts.Record, "record", "", "Record the terminal output to FILE as an asciicast v2 recording")
+
+	flag.StringVar(&opts.Save, "save", "", "Save the tree to FILE, so it can be grown again with --load")
+	flag.StringVar(&opts.Load, "load", "", "Load a tree saved with --save (other flags override it)")
+
 	help := flag.Bool("help", false, "Show help")
 	flag.BoolVar(help, "h", false, "Show help")
 
@@ -787,7 +422,22 @@ func main() {
 		return
 	}
 
+	// Start from a saved tree, with flags given on the command line on top
+	loaded := opts.Load != ""
+	if loaded {
+		saved, err := loadTree(opts.Load)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		*config = *saved
+		flag.Parse() // Re-apply the explicit flags over the loaded config
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
 	// Parse seed
+	fixedSeed := seedStr != "" || loaded
 	if seedStr != "" {
 		if seed, err := strconv.ParseInt(seedStr, 10, 64); err == nil {
 			config.Seed = seed
@@ -795,13 +445,124 @@ func main() {
 			fmt.Printf("Error: invalid seed: %s\n", seedStr)
 			os.Exit(1)
 		}
-	} else {
+	} else if !loaded {
 		config.Seed = time.Now().UnixNano()
 	}
 
+	// Saved trees keep the algorithm they were grown by
+	if !loaded || set["algorithm"] {
+		algorithm, ok := bonsai.ParseAlgorithm(algorithmStr)
+		if !ok {
+			fmt.Printf("Error: invalid algorithm: %s\n", algorithmStr)
+			os.Exit(1)
+		}
+		config.Algorithm = algorithm
+	}
+
+	// Apply the glyph theme, with leaves and strokes given as flags on top
+	if !loaded || set["glyphs"] {
+		theme, err := glyphTheme(glyphsStr)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		theme.Apply(config)
+		if len(theme.Leaves) > 0 && !set["leaf"] && !set["c"] {
+			config.Leaves = theme.Leaves
+			leavesStr = ""
+		}
+	}
+
+	// Apply the color theme
+	if !loaded || set["theme"] {
+		p, err := palette(themeStr)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		config.Palette = p
+	}
+	if !loaded || set["shading"] {
+		shading, ok := bonsai.ParseShading(shadingStr)
+		if !ok {
+			fmt.Printf("Error: invalid shading: %s\n", shadingStr)
+			os.Exit(1)
+		}
+		config.Shading = shading
+	}
+
 	// Parse leaves
-	if leavesStr != "" {
-		config.Leaves = strings.Split(leavesStr, ",")
+	if leavesStr != "" && (!loaded || set["leaf"] || set["c"]) {
+		leaves, err := bonsai.ParseLeaves(leavesStr)
+		if err != nil {
+			fmt.Printf("Error: invalid leaves: %v\n", err)
+			os.Exit(1)
+		}
+		config.Leaves = leaves
+	}
+	for _, l := range []struct {
+		name  string
+		value string
+		dst   *[]string
+	}{{"dying-leaf", dyingStr, &config.DyingLeaves}, {"dead-leaf", deadStr, &config.DeadLeaves}} {
+		if !set[l.name] {
+			continue
+		}
+		leaves, err := bonsai.ParseLeaves(l.value)
+		if err != nil {
+			fmt.Printf("Error: invalid %s: %v\n", l.name, err)
+			os.Exit(1)
+		}
+		*l.dst = leaves
+	}
+
+	// Parse branch glyphs
+	for _, s := range []struct {
+		name  string
+		value string
+		dst   *bonsai.Strokes
+	}{{"trunk", trunkStr, &config.TrunkGlyphs}, {"shoot", shootStr, &config.ShootGlyphs}} {
+		if !set[s.name] {
+			continue
+		}
+		strokes, err := bonsai.ParseStrokes(s.value)
+		if err != nil {
+			fmt.Printf("Error: invalid %s glyphs: %v\n", s.name, err)
+			os.Exit(1)
+		}
+		*s.dst = strokes
+	}
+
+	// Parse message position
+	if !loaded || set["message-pos"] {
+		pos, ok := bonsai.ParseMessagePosition(messagePos)
+		if !ok {
+			fmt.Printf("Error: invalid message position: %s\n", messagePos)
+			os.Exit(1)
+		}
+		config.MessagePos = pos
+	}
+
+	// Parse draw style
+	if braille {
+		style, set["style"] = string(bonsai.StyleBraille), true
+	}
+	if !loaded || set["style"] {
+		s, ok := bonsai.ParseDrawStyle(style)
+		if !ok {
+			fmt.Printf("Error: invalid style: %s\n", style)
+			os.Exit(1)
+		}
+		config.Style = s
+	}
+	if set["leaf-layer"] {
+		switch layer := bonsai.LeafLayer(leafLayer); layer {
+		case bonsai.LeavesFront, bonsai.LeavesBehind:
+			config.LeafLayer = layer
+		default:
+			fmt.Printf("Error: invalid leaf layer: %s\n", leafLayer)
+			os.Exit(1)
+		}
 	}
 
 	// Handle no-color flag
@@ -809,56 +570,172 @@ func main() {
 		config.UseColors = false
 	}
 
-	// Validate configuration
-	if config.LifeStart < 0 || config.LifeStart > 200 {
-		fmt.Println("Error: life must be between 0 and 200")
-		os.Exit(1)
+	// Colors are fitted to what the terminal can show. Images keep them all.
+	depth := bonsai.DetectColorDepth(os.Getenv, term.IsTerminal(int(os.Stdout.Fd())))
+	if depthStr != "auto" {
+		d, ok := bonsai.ParseColorDepth(depthStr)
+		if !ok {
+			fmt.Printf("Error: invalid color depth: %s\n", depthStr)
+			os.Exit(1)
+		}
+		depth = d
 	}
-	if config.Multiplier < 0 || config.Multiplier > 20 {
-		fmt.Println("Error: multiplier must be between 0 and 20")
-		os.Exit(1)
+	if !config.UseColors {
+		depth = bonsai.DepthNone
 	}
-	if config.BaseType < 0 || config.BaseType > 2 {
-		fmt.Println("Error: base type must be 0, 1, or 2")
-		os.Exit(1)
+
+	// Pick the theme colors for the terminal background. It belongs to the
+	// terminal rather than the tree, so loaded trees follow it too.
+	exporting := opts.Output != "" || opts.Format != ""
+	config.Background = bonsai.BackgroundDark
+	if backgroundStr == "auto" {
+		if config.UseColors && depth != bonsai.DepthNone && !exporting {
+			if bg, ok := queryBackground(200 * time.Millisecond); ok {
+				config.Background = bonsai.BackgroundOf(bg)
+			}
+		}
+	} else {
+		bg, ok := bonsai.ParseBackground(backgroundStr)
+		if !ok {
+			fmt.Printf("Error: invalid background: %s\n", backgroundStr)
+			os.Exit(1)
+		}
+		config.Background = bg
+	}
+
+	// Size the canvas to the terminal unless it is given, saved trees keep
+	// their own size. A canvas of another size is placed in the terminal.
+	viewWidth, viewHeight, tty := getTerminalSize()
+	termHeight := viewHeight
+	if opts.PrintTree && tty && (config.BaseType > 0 || config.Message != "") {
+		// Leave a line for the prompt below a pot or message. Without
+		// them the bottom row holds the trunk and is kept.
+		viewHeight--
+	}
+	if !loaded {
+		config.Width, config.Height = viewWidth, viewHeight
+	}
+	if set["width"] {
+		config.Width = width
+	}
+	if set["height"] {
+		config.Height = height
+	}
+	// A canvas that follows the terminal is drawn as it is, a fixed one is
+	// placed in it along with any message below it
+	place := func(tree *bonsai.Tree, r bonsai.Renderer) bonsai.Renderer {
+		if !tty || !set["width"] && !set["height"] && !loaded {
+			return r
+		}
+		width, height := tree.Size()
+		return bonsai.Place(r, width, height, viewWidth, viewHeight)
 	}
-	if config.TimeStep < 0 {
-		fmt.Println("Error: time step must be non-negative")
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Exports write a finished tree and never touch the terminal
+	if exporting {
+		if err := exportTree(context.Background(), config, opts, depth); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Record everything drawn to the terminal
+	if opts.Record != "" {
+		f, err := os.Create(opts.Record)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		cast, err := bonsai.NewCastWriter(f, viewWidth, termHeight, map[string]string{
+			"TERM":  os.Getenv("TERM"),
+			"SHELL": os.Getenv("SHELL"),
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		screen = io.MultiWriter(os.Stdout, cast)
+	}
+
 	// Hide cursor
-	fmt.Print("\033[?25l")
-	defer fmt.Print("\033[?25h") // Show cursor on exit
+	fmt.Fprint(screen, "\033[?25l")
+	defer fmt.Fprint(screen, "\033[?25h") // Show cursor on exit
 
 	// Save console state and setup signal handling (only for interactive modes)
-	if !config.PrintTree {
+	if !opts.PrintTree {
 		saveConsole()
 		defer restoreConsole()
 		setupSignalHandler()
 	}
 
+	ctx := context.Background()
+
+	// Interactive modes draw only what changed, so a new tree replaces the
+	// last one without the screen going blank in between
+	var diff *bonsai.DiffRenderer
+	if !opts.PrintTree {
+		clearScreen()
+		diff = bonsai.NewDiffRenderer(screen)
+	}
+
 	// Main loop
-	for {
+	for first := true; ; first = false {
 		// In infinite mode, generate a new seed for each tree (unless original seed was explicitly set)
-		if config.Infinite && seedStr == "" {
+		if opts.Infinite && !fixedSeed {
 			config.Seed = time.Now().UnixNano()
 		}
+		if diff != nil && !first {
+			diff.Clear()
+		}
 
-		tree := NewBonsaiTree(config)
-		tree.GrowTree()
+		tree := bonsai.NewTree(config)
+		var live bonsai.Renderer
+		switch {
+		case config.Live && diff != nil:
+			live = place(tree, bonsai.Downsample(diff, depth))
+		case config.Live:
+			live = place(tree, bonsai.Downsample(bonsai.NewTerminalRenderer(screen), depth))
+		}
+		if err := tree.GrowWith(ctx, live); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.Save != "" {
+			if err := saveTree(opts.Save, config); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if !config.Live {
+			var r bonsai.Renderer = bonsai.NewTextRenderer(screen, config.UseColors)
+			if diff != nil {
+				r = diff
+			}
+			if err := tree.RenderWith(ctx, place(tree, bonsai.Downsample(r, depth))); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		if config.PrintTree {
+		if opts.PrintTree {
 			// Just print and exit
 			break
 		}
 
-		if config.Infinite {
-			time.Sleep(time.Duration(config.TimeWait * float64(time.Second)))
+		if opts.Infinite {
+			time.Sleep(time.Duration(opts.TimeWait * float64(time.Second)))
 			// Check for interrupt
 			exec.Command("stty", "-cbreak", "echo").Run()
 		} else {
-			tree.MoveCursor(1, tree.config.Height+2)
+			fmt.Fprintf(screen, "\033[%d;%dH", viewHeight+2, 1)
 			fmt.Scanln()
 			break
 		}